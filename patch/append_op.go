@@ -0,0 +1,22 @@
+package patch
+
+// AppendOp appends all items of the array value to the array found at the path.
+// Missing optional arrays are created (ex: /instance_groups/name=api/jobs?).
+type AppendOp struct {
+	Path  Pointer
+	Value interface{} // will be cloned using yaml library
+}
+
+func (op AppendOp) Apply(doc interface{}) (interface{}, error) {
+	items, err := arrayValue(op.Value, "append", op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	return arrayUpdate{
+		Path: op.Path,
+		Update: func(array []interface{}, _ Pointer) ([]interface{}, error) {
+			return append(array, items...), nil
+		},
+	}.Apply(doc)
+}
@@ -0,0 +1,111 @@
+package patch_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/stuart-pollock/go-patch/patch"
+)
+
+var _ = Describe("AppendOp.Apply", func() {
+	It("appends all items to the array", func() {
+		doc := map[interface{}]interface{}{
+			"releases": []interface{}{"a"},
+		}
+
+		res, err := AppendOp{Path: MustNewPointerFromString("/releases"), Value: []interface{}{"b", "c"}}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(map[interface{}]interface{}{
+			"releases": []interface{}{"a", "b", "c"},
+		}))
+	})
+
+	It("appends items to the array found via matching index tokens", func() {
+		doc := map[interface{}]interface{}{
+			"instance_groups": []interface{}{
+				map[interface{}]interface{}{"name": "api", "jobs": []interface{}{"capi"}},
+			},
+		}
+
+		res, err := AppendOp{
+			Path:  MustNewPointerFromString("/instance_groups/name=api/jobs"),
+			Value: []interface{}{"metrics", map[interface{}]interface{}{"name": "syslog"}},
+		}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(map[interface{}]interface{}{
+			"instance_groups": []interface{}{
+				map[interface{}]interface{}{
+					"name": "api",
+					"jobs": []interface{}{"capi", "metrics", map[interface{}]interface{}{"name": "syslog"}},
+				},
+			},
+		}))
+	})
+
+	It("creates missing optional arrays", func() {
+		doc := map[interface{}]interface{}{
+			"instance_groups": []interface{}{
+				map[interface{}]interface{}{"name": "api", "azs": nil},
+			},
+		}
+
+		res, err := AppendOp{Path: MustNewPointerFromString("/instance_groups/name=worker?/jobs?"), Value: []interface{}{"worker"}}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		res, err = AppendOp{Path: MustNewPointerFromString("/instance_groups/name=api/azs"), Value: []interface{}{"z1"}}.Apply(res)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(map[interface{}]interface{}{
+			"instance_groups": []interface{}{
+				map[interface{}]interface{}{"name": "api", "azs": []interface{}{"z1"}},
+				map[interface{}]interface{}{"name": "worker", "jobs": []interface{}{"worker"}},
+			},
+		}))
+	})
+
+	It("appends items to every array matched by wildcard", func() {
+		doc := []interface{}{
+			map[interface{}]interface{}{"azs": []interface{}{"z1"}},
+			map[interface{}]interface{}{"azs": []interface{}{}},
+		}
+
+		res, err := AppendOp{Path: MustNewPointerFromString("/*/azs"), Value: []interface{}{"z2"}}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal([]interface{}{
+			map[interface{}]interface{}{"azs": []interface{}{"z1", "z2"}},
+			map[interface{}]interface{}{"azs": []interface{}{"z2"}},
+		}))
+	})
+
+	It("does not modify appended value", func() {
+		item := map[interface{}]interface{}{"name": "a"}
+
+		res, err := AppendOp{Path: MustNewPointerFromString("/items"), Value: []interface{}{item}}.Apply(
+			map[interface{}]interface{}{"items": []interface{}{}})
+		Expect(err).ToNot(HaveOccurred())
+
+		res.(map[interface{}]interface{})["items"].([]interface{})[0].(map[interface{}]interface{})["name"] = "b"
+		Expect(item).To(Equal(map[interface{}]interface{}{"name": "a"}))
+	})
+
+	It("returns an error if value is not an array", func() {
+		_, err := AppendOp{Path: MustNewPointerFromString("/items"), Value: "a"}.Apply(map[interface{}]interface{}{"items": []interface{}{}})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected value to be an array to append at path '/items' but found 'string'"))
+	})
+
+	It("returns an error if target is not an array", func() {
+		_, err := AppendOp{Path: MustNewPointerFromString("/items"), Value: []interface{}{"a"}}.Apply(map[interface{}]interface{}{"items": "a"})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find an array at path '/items' but found 'string'"))
+	})
+
+	It("returns an error if target is missing", func() {
+		_, err := AppendOp{Path: MustNewPointerFromString("/items"), Value: []interface{}{"a"}}.Apply(map[interface{}]interface{}{})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find a map key 'items' for path '/items' (found no other map keys)"))
+	})
+})
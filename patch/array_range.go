@@ -0,0 +1,46 @@
+package patch
+
+import (
+	"fmt"
+)
+
+type ArrayRange struct {
+	Start int
+	End   int
+	ToEnd bool
+	Array []interface{}
+	Path  Pointer
+}
+
+// Concrete returns start (inclusive) and end (exclusive) positions of the range
+func (r ArrayRange) Concrete() (int, int, error) {
+	start, err := r.bound(r.Start)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	end := len(r.Array)
+
+	if !r.ToEnd {
+		end, err = r.bound(r.End)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+
+	if start > end {
+		return 0, 0, fmt.Errorf("Expected range start '%d' to not be after range end '%d' for path '%s'", start, end, r.Path)
+	}
+
+	return start, end, nil
+}
+
+// bound converts range bound to a position in the array;
+// unlike array index it may refer to the position after last item
+func (r ArrayRange) bound(idx int) (int, error) {
+	if idx == len(r.Array) {
+		return idx, nil
+	}
+
+	return ArrayIndex{Index: idx, Array: r.Array, Path: r.Path}.Concrete()
+}
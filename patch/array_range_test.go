@@ -0,0 +1,58 @@
+package patch_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/stuart-pollock/go-patch/patch"
+)
+
+var _ = Describe("ArrayRange", func() {
+	dummyPath := MustNewPointerFromString("")
+
+	Describe("Concrete", func() {
+		It("returns start and end positions", func() {
+			start, end, err := ArrayRange{Start: 1, End: 3, Array: []interface{}{1, 2, 3}, Path: dummyPath}.Concrete()
+			Expect(err).ToNot(HaveOccurred())
+			Expect([]int{start, end}).To(Equal([]int{1, 3}))
+
+			start, end, err = ArrayRange{Start: 0, End: 0, Array: []interface{}{1, 2, 3}, Path: dummyPath}.Concrete()
+			Expect(err).ToNot(HaveOccurred())
+			Expect([]int{start, end}).To(Equal([]int{0, 0}))
+		})
+
+		It("returns end of array if range continues to the end", func() {
+			start, end, err := ArrayRange{Start: 1, ToEnd: true, Array: []interface{}{1, 2, 3}, Path: dummyPath}.Concrete()
+			Expect(err).ToNot(HaveOccurred())
+			Expect([]int{start, end}).To(Equal([]int{1, 3}))
+
+			start, end, err = ArrayRange{ToEnd: true, Array: []interface{}{}, Path: dummyPath}.Concrete()
+			Expect(err).ToNot(HaveOccurred())
+			Expect([]int{start, end}).To(Equal([]int{0, 0}))
+		})
+
+		It("wraps around negative positions", func() {
+			start, end, err := ArrayRange{Start: -2, End: -1, Array: []interface{}{1, 2, 3}, Path: dummyPath}.Concrete()
+			Expect(err).ToNot(HaveOccurred())
+			Expect([]int{start, end}).To(Equal([]int{1, 2}))
+
+			start, end, err = ArrayRange{Start: -3, ToEnd: true, Array: []interface{}{1, 2, 3}, Path: dummyPath}.Concrete()
+			Expect(err).ToNot(HaveOccurred())
+			Expect([]int{start, end}).To(Equal([]int{0, 3}))
+		})
+
+		It("returns an error if positions are out of bounds", func() {
+			_, _, err := ArrayRange{Start: 0, End: 4, Array: []interface{}{1, 2, 3}, Path: dummyPath}.Concrete()
+			Expect(err).To(Equal(OpMissingIndexErr{4, []interface{}{1, 2, 3}, dummyPath}))
+
+			_, _, err = ArrayRange{Start: -4, End: 1, Array: []interface{}{1, 2, 3}, Path: dummyPath}.Concrete()
+			Expect(err).To(Equal(OpMissingIndexErr{-4, []interface{}{1, 2, 3}, dummyPath}))
+		})
+
+		It("returns an error if start is after end", func() {
+			_, _, err := ArrayRange{Start: 2, End: 1, Array: []interface{}{1, 2, 3}, Path: dummyPath}.Concrete()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected range start '2' to not be after range end '1' for path ''"))
+		})
+	})
+})
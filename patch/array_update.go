@@ -0,0 +1,115 @@
+package patch
+
+import (
+	"fmt"
+)
+
+// arrayUpdate replaces array found at the path (or every array matched by it)
+// with the result of the update function; missing optional values
+// and null values are updated as empty arrays unless KeepMissing is set
+// (in which case they are left as is)
+type arrayUpdate struct {
+	Path        Pointer
+	Update      func(array []interface{}, path Pointer) ([]interface{}, error)
+	KeepMissing bool
+}
+
+func (u arrayUpdate) Apply(doc interface{}) (interface{}, error) {
+	if isMultiMatch(u.Path) {
+		return u.applyAll(doc)
+	}
+
+	target, err := FindOp{Path: u.Path}.Apply(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	if target == nil && u.KeepMissing {
+		return doc, nil
+	}
+
+	updated, err := u.update(target, u.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ReplaceOp{Path: u.Path, Value: updated}.Apply(doc)
+}
+
+// applyAll updates every array matched by the path
+func (u arrayUpdate) applyAll(doc interface{}) (interface{}, error) {
+	foundVals, err := FindAllOp{Path: u.Path}.Resolve(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, foundVal := range foundVals {
+		if foundVal.Value == nil && u.KeepMissing {
+			continue
+		}
+
+		updated, err := u.update(foundVal.Value, foundVal.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		doc, err = ReplaceOp{Path: foundVal.Path, Value: updated}.Apply(doc)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return doc, nil
+}
+
+func (u arrayUpdate) update(target interface{}, path Pointer) ([]interface{}, error) {
+	if target == nil {
+		return u.Update([]interface{}{}, path)
+	}
+
+	typedTarget, ok := target.([]interface{})
+	if !ok {
+		return nil, NewOpArrayMismatchTypeErr(path, target)
+	}
+
+	// Shallow copy is enough since replace operation clones resulting value
+	return u.Update(append([]interface{}{}, typedTarget...), path)
+}
+
+// arrayValue returns items of the value that is expected to be an array
+func arrayValue(val interface{}, action string, path Pointer) ([]interface{}, error) {
+	typedVal, ok := val.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("Expected value to be an array to %s at path '%s' but found '%T'", action, path, val)
+	}
+	return typedVal, nil
+}
+
+// arrayItemKey identifies array items by the value found at the Key
+// (which may refer to nested values, ex: release.name), by the value found at
+// the By pointer relative to the item, or by the entire item if neither is set
+type arrayItemKey struct {
+	Key string
+	By  Pointer
+}
+
+func (k arrayItemKey) value(item interface{}, action string, path Pointer) (interface{}, error) {
+	switch {
+	case len(k.Key) > 0:
+		val, found := nestedValue(item, k.Key)
+		if !found {
+			return nil, fmt.Errorf("Expected to find key '%s' in %s item for path '%s'", k.Key, action, path)
+		}
+		return val, nil
+
+	case k.By.IsSet():
+		val, err := FindOp{Path: k.By}.Apply(item)
+		if err != nil {
+			return nil, fmt.Errorf("Expected to find value at '%s' in %s item for path '%s': %s", k.By, action, path, err)
+		}
+		return val, nil
+
+	default:
+		return item, nil
+	}
+}
@@ -0,0 +1,30 @@
+package patch
+
+// ConcatOp appends all items of the array found at from path
+// to the array found at the path.
+// Missing optional arrays are created (ex: /instance_groups/name=api/jobs?).
+type ConcatOp struct {
+	Path Pointer
+	From Pointer
+}
+
+func (op ConcatOp) Apply(doc interface{}) (interface{}, error) {
+	val, err := FindOp{Path: op.From}.Apply(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	items, ok := val.([]interface{})
+	if !ok {
+		return nil, NewOpArrayMismatchTypeErr(op.From, val)
+	}
+
+	// Items are cloned by replace operation so that
+	// future modifications of the copies do not affect the originals
+	return arrayUpdate{
+		Path: op.Path,
+		Update: func(array []interface{}, _ Pointer) ([]interface{}, error) {
+			return append(array, items...), nil
+		},
+	}.Apply(doc)
+}
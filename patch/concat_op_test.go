@@ -0,0 +1,55 @@
+package patch_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/stuart-pollock/go-patch/patch"
+)
+
+var _ = Describe("ConcatOp.Apply", func() {
+	It("appends copies of all items of the array found at from path", func() {
+		doc := map[interface{}]interface{}{
+			"instance_groups": []interface{}{
+				map[interface{}]interface{}{"name": "api", "jobs": []interface{}{"capi"}},
+				map[interface{}]interface{}{"name": "worker", "jobs": []interface{}{"worker", "metrics"}},
+			},
+		}
+
+		res, err := ConcatOp{
+			Path: MustNewPointerFromString("/instance_groups/name=api/jobs"),
+			From: MustNewPointerFromString("/instance_groups/name=worker/jobs"),
+		}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(map[interface{}]interface{}{
+			"instance_groups": []interface{}{
+				map[interface{}]interface{}{"name": "api", "jobs": []interface{}{"capi", "worker", "metrics"}},
+				map[interface{}]interface{}{"name": "worker", "jobs": []interface{}{"worker", "metrics"}},
+			},
+		}))
+	})
+
+	It("concatenates array with itself", func() {
+		res, err := ConcatOp{Path: MustNewPointerFromString("/a"), From: MustNewPointerFromString("/a")}.Apply(
+			map[interface{}]interface{}{"a": []interface{}{1, 2}})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(map[interface{}]interface{}{"a": []interface{}{1, 2, 1, 2}}))
+	})
+
+	It("creates missing optional arrays", func() {
+		res, err := ConcatOp{Path: MustNewPointerFromString("/b?"), From: MustNewPointerFromString("/a")}.Apply(
+			map[interface{}]interface{}{"a": []interface{}{1}})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(map[interface{}]interface{}{"a": []interface{}{1}, "b": []interface{}{1}}))
+	})
+
+	It("returns an error if from is not an array", func() {
+		_, err := ConcatOp{Path: MustNewPointerFromString("/a"), From: MustNewPointerFromString("/b")}.Apply(
+			map[interface{}]interface{}{"a": []interface{}{}, "b": "b"})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find an array at path '/b' but found 'string'"))
+	})
+})
@@ -0,0 +1,22 @@
+package patch
+
+type CopyOp struct {
+	Path Pointer
+	From Pointer
+}
+
+func (op CopyOp) Apply(doc interface{}) (interface{}, error) {
+	val, err := FindOp{Path: op.From}.Apply(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	// Value is cloned by replace operation so that
+	// future modifications of the copy do not affect the original
+	doc, err = ReplaceOp{Path: op.Path, Value: val}.Apply(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
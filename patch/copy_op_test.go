@@ -0,0 +1,147 @@
+package patch_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/stuart-pollock/go-patch/patch"
+)
+
+var _ = Describe("CopyOp.Apply", func() {
+	It("returns an error if from does not exist", func() {
+		doc := map[interface{}]interface{}{
+			"xyz": map[interface{}]interface{}{
+				"nested": "blah",
+			},
+		}
+
+		_, err := CopyOp{Path: MustNewPointerFromString("/abc?"), From: MustNewPointerFromString("/xyz/new_nested")}.Apply(doc)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find a map key 'new_nested' for path '/xyz/new_nested' (found map keys: 'nested')"))
+	})
+
+	It("returns an error if path is not permissible", func() {
+		doc := map[interface{}]interface{}{
+			"xyz": "xyz",
+		}
+
+		_, err := CopyOp{Path: MustNewPointerFromString("/abc/def/ghi"), From: MustNewPointerFromString("/xyz")}.Apply(doc)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find a map key 'abc' for path '/abc' (found map keys: 'xyz')"))
+	})
+
+	It("copies entire document into a map", func() {
+		res, err := CopyOp{Path: MustNewPointerFromString("/abc?"), From: MustNewPointerFromString("")}.Apply(map[interface{}]interface{}{"xyz": "xyz"})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(map[interface{}]interface{}{
+			"abc": map[interface{}]interface{}{"xyz": "xyz"},
+			"xyz": "xyz",
+		}))
+	})
+
+	It("copies value within a map", func() {
+		doc := map[interface{}]interface{}{
+			"xyz": "xyz",
+		}
+
+		res, err := CopyOp{Path: MustNewPointerFromString("/abc?"), From: MustNewPointerFromString("/xyz")}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(map[interface{}]interface{}{
+			"abc": "xyz",
+			"xyz": "xyz",
+		}))
+	})
+
+	It("copies value so that modifying copy does not affect original", func() {
+		doc := map[interface{}]interface{}{
+			"abc": map[interface{}]interface{}{
+				"def": []interface{}{1},
+			},
+		}
+
+		res, err := CopyOp{Path: MustNewPointerFromString("/xyz?"), From: MustNewPointerFromString("/abc")}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		res, err = ReplaceOp{Path: MustNewPointerFromString("/xyz/def/-"), Value: 2}.Apply(res)
+		Expect(err).ToNot(HaveOccurred())
+
+		res, err = ReplaceOp{Path: MustNewPointerFromString("/xyz/ghi?"), Value: 3}.Apply(res)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(map[interface{}]interface{}{
+			"abc": map[interface{}]interface{}{
+				"def": []interface{}{1},
+			},
+			"xyz": map[interface{}]interface{}{
+				"def": []interface{}{1, 2},
+				"ghi": 3,
+			},
+		}))
+	})
+
+	It("copies matching array item between arrays of maps", func() {
+		doc := map[interface{}]interface{}{
+			"instance_groups": []interface{}{
+				map[interface{}]interface{}{
+					"name": "api",
+					"jobs": []interface{}{
+						map[interface{}]interface{}{"name": "capi", "properties": map[interface{}]interface{}{"a": 1}},
+					},
+				},
+				map[interface{}]interface{}{
+					"name": "worker",
+					"jobs": []interface{}{
+						map[interface{}]interface{}{"name": "worker"},
+					},
+				},
+			},
+		}
+
+		res, err := CopyOp{
+			Path: MustNewPointerFromString("/instance_groups/name=worker/jobs/name=worker:before"),
+			From: MustNewPointerFromString("/instance_groups/name=api/jobs/name=capi"),
+		}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(map[interface{}]interface{}{
+			"instance_groups": []interface{}{
+				map[interface{}]interface{}{
+					"name": "api",
+					"jobs": []interface{}{
+						map[interface{}]interface{}{"name": "capi", "properties": map[interface{}]interface{}{"a": 1}},
+					},
+				},
+				map[interface{}]interface{}{
+					"name": "worker",
+					"jobs": []interface{}{
+						map[interface{}]interface{}{"name": "capi", "properties": map[interface{}]interface{}{"a": 1}},
+						map[interface{}]interface{}{"name": "worker"},
+					},
+				},
+			},
+		}))
+	})
+
+	It("copies relative array item into optionally created nested map", func() {
+		doc := map[interface{}]interface{}{
+			"abc": []interface{}{1, 2, 3},
+		}
+
+		res, err := CopyOp{
+			Path: MustNewPointerFromString("/xyz?/items/name=item?/value"),
+			From: MustNewPointerFromString("/abc/0:next"),
+		}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(map[interface{}]interface{}{
+			"abc": []interface{}{1, 2, 3},
+			"xyz": map[interface{}]interface{}{
+				"items": []interface{}{
+					map[interface{}]interface{}{"name": "item", "value": 2},
+				},
+			},
+		}))
+	})
+})
@@ -0,0 +1,50 @@
+package patch
+
+// DedupeOp removes duplicate items of the array found at the path
+// (or every array matched by it) keeping the first occurrence of each item;
+// items are compared deeply, by the value found at Key
+// or by the value found at By pointer relative to each item.
+type DedupeOp struct {
+	Path Pointer
+	Key  string
+	By   Pointer
+}
+
+func (op DedupeOp) Apply(doc interface{}) (interface{}, error) {
+	key := arrayItemKey{Key: op.Key, By: op.By}
+
+	return arrayUpdate{
+		Path:        op.Path,
+		KeepMissing: true,
+		Update: func(array []interface{}, path Pointer) ([]interface{}, error) {
+			var result, seenVals []interface{}
+
+			for _, item := range array {
+				val, err := key.value(item, "deduplicated", path)
+				if err != nil {
+					return nil, err
+				}
+
+				if !op.seen(seenVals, val) {
+					result = append(result, item)
+					seenVals = append(seenVals, val)
+				}
+			}
+
+			if result == nil {
+				result = []interface{}{}
+			}
+
+			return result, nil
+		},
+	}.Apply(doc)
+}
+
+func (DedupeOp) seen(vals []interface{}, val interface{}) bool {
+	for _, seenVal := range vals {
+		if valuesEqual(seenVal, val) {
+			return true
+		}
+	}
+	return false
+}
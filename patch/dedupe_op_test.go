@@ -0,0 +1,88 @@
+package patch_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v2"
+
+	. "github.com/stuart-pollock/go-patch/patch"
+)
+
+var _ = Describe("DedupeOp.Apply", func() {
+	fromYAML := func(str string) interface{} {
+		var val interface{}
+		err := yaml.Unmarshal([]byte(str), &val)
+		Expect(err).ToNot(HaveOccurred())
+		return val
+	}
+
+	It("removes items that are deeply equal to previous items", func() {
+		res, err := DedupeOp{Path: MustNewPointerFromString("/items")}.Apply(fromYAML(`
+items: [z1, {name: a}, z1, "1", 1, {name: a}, {name: a, b: c}]
+`))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(fromYAML(`
+items: [z1, {name: a}, "1", 1, {name: a, b: c}]
+`)))
+	})
+
+	It("removes items that have the same key value as previous items", func() {
+		doc := `
+releases:
+- {name: capi, version: 1}
+- {release: {name: capi}, name: uaa, version: 1}
+- {name: capi, version: 2}
+- {name: uaa, version: 2}
+`
+
+		res, err := DedupeOp{Path: MustNewPointerFromString("/releases"), Key: "name"}.Apply(fromYAML(doc))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(fromYAML(`
+releases:
+- {name: capi, version: 1}
+- {release: {name: capi}, name: uaa, version: 1}
+`)))
+
+		res, err = DedupeOp{Path: MustNewPointerFromString("/releases"), By: MustNewPointerFromString("/version")}.Apply(fromYAML(doc))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(fromYAML(`
+releases:
+- {name: capi, version: 1}
+- {name: capi, version: 2}
+`)))
+	})
+
+	It("removes duplicates from every array matched by wildcard", func() {
+		res, err := DedupeOp{Path: MustNewPointerFromString("/instance_groups/*/azs")}.Apply(fromYAML(`
+instance_groups:
+- azs: [z1, z1]
+- azs: [z2, z1, z2]
+`))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(fromYAML(`
+instance_groups:
+- azs: [z1]
+- azs: [z2, z1]
+`)))
+	})
+
+	It("leaves missing and null arrays as is", func() {
+		res, err := DedupeOp{Path: MustNewPointerFromString("/x?/y")}.Apply(fromYAML(`{azs: [z1]}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(fromYAML(`{azs: [z1]}`)))
+
+		res, err = DedupeOp{Path: MustNewPointerFromString("/azs")}.Apply(fromYAML(`{azs: null}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(fromYAML(`{azs: null}`)))
+	})
+
+	It("returns an error if item does not have the key", func() {
+		_, err := DedupeOp{Path: MustNewPointerFromString("/releases"), Key: "name"}.Apply(fromYAML(`{releases: [{version: 1}]}`))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find key 'name' in deduplicated item for path '/releases'"))
+	})
+})
@@ -1,11 +1,7 @@
 package patch
 
 import (
-	"fmt"
 	"reflect"
-	"sort"
-
-	"gopkg.in/yaml.v2"
 )
 
 type Diff struct {
@@ -29,30 +25,63 @@ func (d Diff) Calculate() Ops {
 	return newOps
 }
 
+// CalculateMergePatch returns RFC 7386 JSON Merge Patch document
+// that turns left document into right document.
+// Since null values remove keys in merge patch,
+// keys that are set to null in right document cannot be represented.
+func (d Diff) CalculateMergePatch() interface{} {
+	return d.calculateMergePatch(d.Left, d.Right)
+}
+
+func (d Diff) calculateMergePatch(left, right interface{}) interface{} {
+	typedLeft, ok := newGenericMap(left)
+	if !ok {
+		return right
+	}
+
+	typedRight, ok := newGenericMap(right)
+	if !ok {
+		return right
+	}
+
+	patch := typedRight.Type().New()
+
+	for _, k := range typedLeft.Keys() {
+		if _, found := typedRight.Get(k); !found {
+			patch.Set(k, nil)
+		}
+	}
+
+	for _, k := range typedRight.Keys() {
+		rightVal, _ := typedRight.Get(k)
+		if leftVal, found := typedLeft.Get(k); !found {
+			patch.Set(k, rightVal)
+		} else if !reflect.DeepEqual(leftVal, rightVal) {
+			patch.Set(k, d.calculateMergePatch(leftVal, rightVal))
+		}
+	}
+
+	return patch.Obj()
+}
+
 func (d Diff) calculate(left, right interface{}, tokens []Token) []Op {
 	switch typedLeft := left.(type) {
-	case map[interface{}]interface{}:
-		if typedRight, ok := right.(map[interface{}]interface{}); ok {
+	case map[interface{}]interface{}, map[string]interface{}:
+		leftMap, _ := newGenericMap(left)
+		if rightMap, ok := newGenericMap(right); ok {
 			ops := []Op{}
-			var allKeys []interface{}
-			for k, _ := range typedLeft {
-				allKeys = append(allKeys, k)
-			}
-			for k, _ := range typedRight {
-				if _, found := typedLeft[k]; !found {
+			allKeys := leftMap.Keys()
+			for _, k := range rightMap.Keys() {
+				if _, found := leftMap.Get(k); !found {
 					allKeys = append(allKeys, k)
 				}
 			}
-			sort.SliceStable(allKeys, func(i, j int) bool {
-				iBs, _ := yaml.Marshal(allKeys[i])
-				jBs, _ := yaml.Marshal(allKeys[j])
-				return string(iBs) < string(jBs)
-			})
+			sortYAMLKeys(allKeys)
 			for _, k := range allKeys {
 				newTokens := append([]Token{}, tokens...)
-				if leftVal, found := typedLeft[k]; found {
-					newTokens = append(newTokens, KeyToken{Key: fmt.Sprintf("%s", k)})
-					if rightVal, found := typedRight[k]; found {
+				if leftVal, found := leftMap.Get(k); found {
+					newTokens = append(newTokens, newKeyToken(k))
+					if rightVal, found := rightMap.Get(k); found {
 						ops = append(ops, d.calculate(leftVal, rightVal, newTokens)...)
 					} else { // remove existing
 						ops = append(ops,
@@ -62,11 +91,14 @@ func (d Diff) calculate(left, right interface{}, tokens []Token) []Op {
 					}
 				} else { // add new
 					testOpTokens := append([]Token{}, newTokens...)
-					testOpTokens = append(testOpTokens, KeyToken{Key: fmt.Sprintf("%s", k)})
-					newTokens = append(newTokens, KeyToken{Key: fmt.Sprintf("%s", k), Optional: true})
+					testOpTokens = append(testOpTokens, newKeyToken(k))
+					keyToken := newKeyToken(k)
+					keyToken.Optional = true
+					newTokens = append(newTokens, keyToken)
+					rightVal, _ := rightMap.Get(k)
 					ops = append(ops,
 						TestOp{Path: NewPointer(testOpTokens), Absent: true},
-						ReplaceOp{Path: NewPointer(newTokens), Value: typedRight[k]},
+						ReplaceOp{Path: NewPointer(newTokens), Value: rightVal},
 					)
 				}
 			}
@@ -155,6 +155,21 @@ var _ = Describe("Diff.Calculate", func() {
 		)
 	})
 
+	It("can diff maps with non-string keys", func() {
+		testDiff(
+			map[interface{}]interface{}{80: "http", true: 1, 1.5: "a", "80": "b"},
+			map[interface{}]interface{}{80: "https", nil: 2, 1.5: "a", "80": "b"},
+			[]Op{
+				TestOp{Path: MustNewPointerFromString("/!!int 80"), Value: "http"},
+				ReplaceOp{Path: MustNewPointerFromString("/!!int 80"), Value: "https"},
+				TestOp{Path: MustNewPointerFromString("/!!null null"), Absent: true},
+				ReplaceOp{Path: MustNewPointerFromString("/!!null null?"), Value: 2},
+				TestOp{Path: MustNewPointerFromString("/!!bool true"), Value: 1},
+				RemoveOp{Path: MustNewPointerFromString("/!!bool true")},
+			},
+		)
+	})
+
 	It("can diff arrays", func() {
 		testDiff(
 			[]interface{}{"a", 123},
@@ -243,3 +258,77 @@ var _ = Describe("Diff.Calculate", func() {
 		)
 	})
 })
+
+var _ = Describe("Diff.CalculateMergePatch", func() {
+	testDiff := func(left, right interface{}, expectedPatch interface{}) {
+		patch := Diff{Left: left, Right: right}.CalculateMergePatch()
+		if expectedPatch == nil {
+			Expect(patch).To(BeNil())
+		} else {
+			Expect(patch).To(Equal(expectedPatch))
+		}
+
+		result, err := MergePatchOp{Path: MustNewPointerFromString(""), Value: patch}.Apply(left)
+		Expect(err).ToNot(HaveOccurred())
+
+		if right == nil {
+			Expect(result).To(BeNil())
+		} else {
+			Expect(result).To(Equal(right))
+		}
+	}
+
+	It("returns empty patch if both maps are same", func() {
+		testDiff(
+			map[interface{}]interface{}{"a": 124},
+			map[interface{}]interface{}{"a": 124},
+			map[interface{}]interface{}{},
+		)
+	})
+
+	It("returns right document if either document is not a map", func() {
+		testDiff("a", nil, nil)
+		testDiff(nil, "a", "a")
+		testDiff([]interface{}{1}, []interface{}{1, 2}, []interface{}{1, 2})
+		testDiff(map[interface{}]interface{}{"a": 1}, []interface{}{1}, []interface{}{1})
+		testDiff([]interface{}{1}, map[interface{}]interface{}{"a": 1}, map[interface{}]interface{}{"a": 1})
+	})
+
+	It("adds, removes and replaces keys recursively", func() {
+		testDiff(
+			map[interface{}]interface{}{
+				"same":    1,
+				"removed": 2,
+				"changed": 3,
+				"nested": map[interface{}]interface{}{
+					"same":    1,
+					"removed": 2,
+					"array":   []interface{}{1, 2},
+				},
+				"type": map[interface{}]interface{}{"a": 1},
+			},
+			map[interface{}]interface{}{
+				"same":    1,
+				"changed": 4,
+				"added":   5,
+				"nested": map[interface{}]interface{}{
+					"same":  1,
+					"added": map[interface{}]interface{}{"a": 1},
+					"array": []interface{}{1},
+				},
+				"type": "a",
+			},
+			map[interface{}]interface{}{
+				"removed": nil,
+				"changed": 4,
+				"added":   5,
+				"nested": map[interface{}]interface{}{
+					"removed": nil,
+					"added":   map[interface{}]interface{}{"a": 1},
+					"array":   []interface{}{1},
+				},
+				"type": "a",
+			},
+		)
+	})
+})
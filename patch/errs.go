@@ -26,9 +26,23 @@ func (e OpMismatchTypeErr) Error() string {
 }
 
 type OpMissingMapKeyErr struct {
-	Key  string
-	Path Pointer
-	Obj  map[interface{}]interface{}
+	Key     string
+	Path    Pointer
+	Obj     map[interface{}]interface{}
+	JSONObj map[string]interface{} // set instead of Obj for maps decoded from JSON
+}
+
+func newOpMissingMapKeyErr(key string, path Pointer, obj genericMap) OpMissingMapKeyErr {
+	err := OpMissingMapKeyErr{Key: key, Path: path}
+
+	switch typedObj := obj.Obj().(type) {
+	case map[interface{}]interface{}:
+		err.Obj = typedObj
+	case map[string]interface{}:
+		err.JSONObj = typedObj
+	}
+
+	return err
 }
 
 func (e OpMissingMapKeyErr) Error() string {
@@ -37,11 +51,15 @@ func (e OpMissingMapKeyErr) Error() string {
 }
 
 func (e OpMissingMapKeyErr) siblingKeysErrStr() string {
-	if len(e.Obj) == 0 {
+	obj, _ := newGenericMap(e.Obj)
+	if e.JSONObj != nil {
+		obj, _ = newGenericMap(e.JSONObj)
+	}
+	if obj.Len() == 0 {
 		return "found no other map keys"
 	}
 	var keys []string
-	for key, _ := range e.Obj {
+	for _, key := range obj.Keys() {
 		if keyStr, ok := key.(string); ok {
 			keys = append(keys, keyStr)
 		}
@@ -69,6 +87,16 @@ func (e OpMultipleMatchingIndexErr) Error() string {
 	return fmt.Sprintf("Expected to find exactly one matching array item for path '%s' but found %d", e.Path, len(e.Idxs))
 }
 
+type OpMissingMatchingIndexErr struct {
+	Nth  int
+	Path Pointer
+	Idxs []int
+}
+
+func (e OpMissingMatchingIndexErr) Error() string {
+	return fmt.Sprintf("Expected to find matching array item '%d' for path '%s' but found %d", e.Nth, e.Path, len(e.Idxs))
+}
+
 type OpUnexpectedTokenErr struct {
 	Token Token
 	Path  Pointer
@@ -0,0 +1,296 @@
+package patch
+
+import (
+	"fmt"
+)
+
+// FoundValue is a value found by FindAllOp together with its concrete path:
+// wildcards, key glob patterns and matching index tokens are resolved
+// to map keys and array indices,
+// modifiers are applied and optional markers are dropped.
+type FoundValue struct {
+	Path  Pointer
+	Value interface{}
+}
+
+// FindAllOp finds all values that match the path (which may contain wildcards
+// over arrays and maps, key glob patterns and recursive descent);
+// map keys are matched in sorted order.
+// Optional tokens that do not match anything do not produce any values.
+// Nested values matched by recursive descent are skipped
+// if following tokens cannot be resolved against them.
+type FindAllOp struct {
+	Path Pointer
+}
+
+// Apply returns an array of found values
+func (op FindAllOp) Apply(doc interface{}) (interface{}, error) {
+	foundVals, err := op.Resolve(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	vals := []interface{}{}
+
+	for _, foundVal := range foundVals {
+		vals = append(vals, foundVal.Value)
+	}
+
+	return vals, nil
+}
+
+type findAllCtx struct {
+	Obj    interface{}
+	Tokens []Token // concrete tokens leading to Obj
+}
+
+// Resolve returns found values with their concrete paths in document order.
+// Path with a recursive descent token (that is not optional)
+// must match at least one value.
+func (op FindAllOp) Resolve(doc interface{}) ([]FoundValue, error) {
+	foundVals, err := op.resolve(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(foundVals) == 0 && isRecursive(op.Path) && !isOptionalRecursive(op.Path) {
+		return nil, fmt.Errorf("Expected to find at least one value matching path '%s'", op.Path)
+	}
+
+	return foundVals, nil
+}
+
+func (op FindAllOp) resolve(doc interface{}) ([]FoundValue, error) {
+	tokens := op.Path.Tokens()
+	ctxs := []findAllCtx{{Obj: doc, Tokens: []Token{RootToken{}}}}
+
+	// Tokens following recursive descent filter nested values
+	// instead of failing when they cannot be resolved
+	filtering := false
+
+	for i, token := range tokens[1:] {
+		var nextCtxs []findAllCtx
+
+		if _, ok := token.(AfterLastIndexToken); ok && i == len(tokens)-2 {
+			errMsg := "Expected not to find after last index token in path '%s' (not supported in find operations)"
+			return nil, fmt.Errorf(errMsg, op.Path)
+		}
+
+		if _, ok := token.(RangeToken); ok && i != len(tokens)-2 {
+			return nil, fmt.Errorf("Expected range token to be last in path '%s'", op.Path)
+		}
+
+		if _, ok := token.(RecursiveDescentToken); ok {
+			seen := map[string]struct{}{}
+
+			for _, ctx := range ctxs {
+				descendants(ctx.Obj, ctx.Tokens, func(tokens []Token, obj interface{}) {
+					path := NewPointer(tokens).String()
+					if _, found := seen[path]; !found {
+						seen[path] = struct{}{}
+						nextCtxs = append(nextCtxs, findAllCtx{Obj: obj, Tokens: tokens})
+					}
+				})
+			}
+
+			ctxs = nextCtxs
+			filtering = true
+			continue
+		}
+
+		for _, ctx := range ctxs {
+			nextCtx := func(concreteToken Token, obj interface{}) {
+				nextTokens := append(append([]Token{}, ctx.Tokens...), concreteToken)
+				nextCtxs = append(nextCtxs, findAllCtx{Obj: obj, Tokens: nextTokens})
+			}
+
+			err := op.resolveToken(ctx, token, nextCtx)
+			if err != nil {
+				if filtering {
+					continue
+				}
+				return nil, err
+			}
+		}
+
+		ctxs = nextCtxs
+	}
+
+	foundVals := []FoundValue{}
+
+	for _, ctx := range ctxs {
+		foundVals = append(foundVals, FoundValue{Path: NewPointer(ctx.Tokens), Value: ctx.Obj})
+	}
+
+	return foundVals, nil
+}
+
+func (op FindAllOp) resolveToken(ctx findAllCtx, token Token, addNext func(Token, interface{})) error {
+	currPath := NewPointer(append(append([]Token{}, ctx.Tokens...), token))
+
+	// After last index token that is last is rejected before resolution
+	token = afterLastIndexKey(token, ctx.Obj, false)
+
+	switch typedToken := token.(type) {
+	case AfterLastIndexToken:
+		errMsg := "Expected not to find after last index token in path '%s' (not supported in find operations)"
+		return fmt.Errorf(errMsg, op.Path)
+
+	case IndexToken:
+		typedObj, ok := ctx.Obj.([]interface{})
+		if !ok {
+			return NewOpArrayMismatchTypeErr(currPath, ctx.Obj)
+		}
+
+		idx, err := ArrayIndex{Index: typedToken.Index, Modifiers: typedToken.Modifiers, Array: typedObj, Path: currPath}.Concrete()
+		if err != nil {
+			return err
+		}
+
+		addNext(IndexToken{Index: idx}, typedObj[idx])
+
+	case MatchingIndexToken:
+		typedObj, ok := ctx.Obj.([]interface{})
+		if !ok {
+			return NewOpArrayMismatchTypeErr(currPath, ctx.Obj)
+		}
+
+		idxs := matchingIndexes(typedToken, typedObj)
+
+		if typedToken.Optional && len(idxs) == 0 {
+			return nil
+		}
+
+		idxs, modifiers, err := selectMatchingIndexes(typedToken, idxs, currPath)
+		if err != nil {
+			return err
+		}
+
+		for _, idx := range idxs {
+			idx, err := ArrayIndex{Index: idx, Modifiers: modifiers, Array: typedObj, Path: currPath}.Concrete()
+			if err != nil {
+				return err
+			}
+
+			addNext(IndexToken{Index: idx}, typedObj[idx])
+		}
+
+	case RangeToken:
+		typedObj, ok := ctx.Obj.([]interface{})
+		if !ok {
+			return NewOpArrayMismatchTypeErr(currPath, ctx.Obj)
+		}
+
+		start, end, err := ArrayRange{Start: typedToken.Start, End: typedToken.End, ToEnd: typedToken.ToEnd, Array: typedObj, Path: currPath}.Concrete()
+		if err != nil {
+			return err
+		}
+
+		addNext(RangeToken{Start: start, End: end}, append([]interface{}{}, typedObj[start:end]...))
+
+	case KeyToken:
+		typedObj, ok := newGenericMap(ctx.Obj)
+		if !ok {
+			return NewOpMapMismatchTypeErr(currPath, ctx.Obj)
+		}
+
+		val, found := typedObj.Get(typedToken.mapKey())
+		if !found {
+			if typedToken.Optional {
+				return nil
+			}
+			return newOpMissingMapKeyErr(typedToken.Key, currPath, typedObj)
+		}
+
+		addNext(KeyToken{Key: typedToken.Key, Tag: typedToken.Tag}, val)
+
+	case WildcardToken:
+		if typedObj, ok := newGenericMap(ctx.Obj); ok {
+			for _, key := range sortedKeys(typedObj) {
+				val, _ := typedObj.Get(key)
+				addNext(newKeyToken(key), val)
+			}
+			return nil
+		}
+
+		typedObj, ok := ctx.Obj.([]interface{})
+		if !ok {
+			return OpMismatchTypeErr{"a map or an array", currPath, ctx.Obj}
+		}
+
+		for idx, val := range typedObj {
+			addNext(IndexToken{Index: idx}, val)
+		}
+
+	case KeyGlobToken:
+		typedObj, ok := newGenericMap(ctx.Obj)
+		if !ok {
+			return NewOpMapMismatchTypeErr(currPath, ctx.Obj)
+		}
+
+		for _, key := range matchingKeys(typedToken, typedObj) {
+			val, _ := typedObj.Get(key)
+			addNext(KeyToken{Key: key}, val)
+		}
+
+	default:
+		return OpUnexpectedTokenErr{token, currPath}
+	}
+
+	return nil
+}
+
+// descendants walks the value and all of its nested values in document order
+// (map values in sorted key order)
+func descendants(obj interface{}, tokens []Token, f func([]Token, interface{})) {
+	f(tokens, obj)
+
+	if typedObj, ok := newGenericMap(obj); ok {
+		for _, key := range sortedKeys(typedObj) {
+			val, _ := typedObj.Get(key)
+			descendants(val, append(append([]Token{}, tokens...), newKeyToken(key)), f)
+		}
+		return
+	}
+
+	if typedObj, ok := obj.([]interface{}); ok {
+		for idx, val := range typedObj {
+			descendants(val, append(append([]Token{}, tokens...), IndexToken{Index: idx}), f)
+		}
+	}
+}
+
+// isMultiMatch checks if path may match multiple values
+func isMultiMatch(path Pointer) bool {
+	for _, token := range path.Tokens() {
+		switch typedToken := token.(type) {
+		case WildcardToken, KeyGlobToken, RecursiveDescentToken:
+			return true
+		case MatchingIndexToken:
+			if isAllMatching(typedToken) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isRecursive checks if path contains recursive descent token
+func isRecursive(path Pointer) bool {
+	for _, token := range path.Tokens() {
+		if _, ok := token.(RecursiveDescentToken); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isOptionalRecursive checks if first recursive descent token in path is optional
+func isOptionalRecursive(path Pointer) bool {
+	for _, token := range path.Tokens() {
+		if typedToken, ok := token.(RecursiveDescentToken); ok {
+			return typedToken.Optional
+		}
+	}
+	return false
+}
@@ -0,0 +1,247 @@
+package patch_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/stuart-pollock/go-patch/patch"
+)
+
+var _ = Describe("FindAllOp", func() {
+	var doc interface{}
+
+	BeforeEach(func() {
+		doc = map[interface{}]interface{}{
+			"instance_groups": []interface{}{
+				map[interface{}]interface{}{
+					"name":      "api",
+					"instances": 1,
+					"jobs": []interface{}{
+						map[interface{}]interface{}{"name": "capi"},
+						map[interface{}]interface{}{"name": "nats"},
+					},
+				},
+				map[interface{}]interface{}{
+					"name":      "worker",
+					"instances": 2,
+					"jobs": []interface{}{
+						map[interface{}]interface{}{"name": "worker"},
+					},
+				},
+			},
+		}
+	})
+
+	Describe("Resolve", func() {
+		It("returns all values matched by wildcards with concrete paths", func() {
+			res, err := FindAllOp{Path: MustNewPointerFromString("/instance_groups/*/instances")}.Resolve(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]FoundValue{
+				{Path: MustNewPointerFromString("/instance_groups/0/instances"), Value: 1},
+				{Path: MustNewPointerFromString("/instance_groups/1/instances"), Value: 2},
+			}))
+		})
+
+		It("returns values in document order for multiple wildcards", func() {
+			res, err := FindAllOp{Path: MustNewPointerFromString("/instance_groups/*/jobs/*/name")}.Resolve(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]FoundValue{
+				{Path: MustNewPointerFromString("/instance_groups/0/jobs/0/name"), Value: "capi"},
+				{Path: MustNewPointerFromString("/instance_groups/0/jobs/1/name"), Value: "nats"},
+				{Path: MustNewPointerFromString("/instance_groups/1/jobs/0/name"), Value: "worker"},
+			}))
+		})
+
+		It("resolves matching index tokens, modifiers and optional keys to concrete paths", func() {
+			res, err := FindAllOp{Path: MustNewPointerFromString("/instance_groups/name=api:next/jobs/-1/name?")}.Resolve(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]FoundValue{
+				{Path: MustNewPointerFromString("/instance_groups/1/jobs/0/name"), Value: "worker"},
+			}))
+		})
+
+		It("skips optional tokens that do not match", func() {
+			res, err := FindAllOp{Path: MustNewPointerFromString("/instance_groups/*/jobs/name=capi?/name")}.Resolve(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]FoundValue{
+				{Path: MustNewPointerFromString("/instance_groups/0/jobs/0/name"), Value: "capi"},
+			}))
+
+			res, err = FindAllOp{Path: MustNewPointerFromString("/instance_groups/*/env?/bosh")}.Resolve(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(BeEmpty())
+		})
+
+		It("returns root document for root path", func() {
+			res, err := FindAllOp{Path: MustNewPointerFromString("")}.Resolve(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]FoundValue{{Path: MustNewPointerFromString(""), Value: doc}}))
+		})
+
+		It("returns an error with concrete path if required value is missing", func() {
+			_, err := FindAllOp{Path: MustNewPointerFromString("/instance_groups/*/jobs/name=capi")}.Resolve(doc)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(
+				"Expected to find exactly one matching array item for path '/instance_groups/1/jobs/name=capi' but found 0"))
+
+			_, err = FindAllOp{Path: MustNewPointerFromString("/instance_groups/*/env")}.Resolve(doc)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(
+				"Expected to find a map key 'env' for path '/instance_groups/0/env' (found map keys: 'instances', 'jobs', 'name')"))
+		})
+
+		It("returns all map values matched by wildcard in sorted key order", func() {
+			res, err := FindAllOp{Path: MustNewPointerFromString("/instance_groups/0/*")}.Resolve(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(HaveLen(3))
+			Expect(res[0]).To(Equal(FoundValue{Path: MustNewPointerFromString("/instance_groups/0/instances"), Value: 1}))
+			Expect(res[1].Path).To(Equal(MustNewPointerFromString("/instance_groups/0/jobs")))
+			Expect(res[2]).To(Equal(FoundValue{Path: MustNewPointerFromString("/instance_groups/0/name"), Value: "api"}))
+		})
+
+		It("returns map values under keys of any type with tagged concrete paths", func() {
+			doc := map[interface{}]interface{}{
+				"ports": map[interface{}]interface{}{80: "a", 443: "b", "x": "c", true: "d"},
+			}
+
+			res, err := FindAllOp{Path: MustNewPointerFromString("/ports/*")}.Resolve(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]FoundValue{
+				{Path: MustNewPointerFromString("/ports/!!int 443"), Value: "b"},
+				{Path: MustNewPointerFromString("/ports/!!int 80"), Value: "a"},
+				{Path: MustNewPointerFromString("/ports/!!bool true"), Value: "d"},
+				{Path: MustNewPointerFromString("/ports/x"), Value: "c"},
+			}))
+			Expect(res[1].Path.String()).To(Equal("/ports/!!int 80"))
+
+			res, err = FindAllOp{Path: MustNewPointerFromString("/ports/*0")}.Resolve(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(BeEmpty())
+		})
+
+		It("returns all map values with keys matched by glob pattern", func() {
+			res, err := FindAllOp{Path: MustNewPointerFromString("/instance_groups/1/*s")}.Resolve(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]FoundValue{
+				{Path: MustNewPointerFromString("/instance_groups/1/instances"), Value: 2},
+				{Path: MustNewPointerFromString("/instance_groups/1/jobs"), Value: []interface{}{map[interface{}]interface{}{"name": "worker"}}},
+			}))
+
+			res, err = FindAllOp{Path: MustNewPointerFromString("/instance_groups/*/na*e")}.Resolve(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]FoundValue{
+				{Path: MustNewPointerFromString("/instance_groups/0/name"), Value: "api"},
+				{Path: MustNewPointerFromString("/instance_groups/1/name"), Value: "worker"},
+			}))
+
+			res, err = FindAllOp{Path: MustNewPointerFromString("/instance_groups/*/missing*")}.Resolve(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(BeEmpty())
+		})
+
+		It("returns an error if wildcard is used on neither map nor array", func() {
+			_, err := FindAllOp{Path: MustNewPointerFromString("/instance_groups/0/name/*")}.Resolve(doc)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(
+				"Expected to find a map or an array at path '/instance_groups/0/name/*' but found 'string'"))
+
+			_, err = FindAllOp{Path: MustNewPointerFromString("/instance_groups/*_api")}.Resolve(doc)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(
+				"Expected to find a map at path '/instance_groups/*_api' but found '[]interface {}'"))
+		})
+
+		It("returns values matched by recursive descent at any depth in document order", func() {
+			res, err := FindAllOp{Path: MustNewPointerFromString("/**/name")}.Resolve(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]FoundValue{
+				{Path: MustNewPointerFromString("/instance_groups/0/name"), Value: "api"},
+				{Path: MustNewPointerFromString("/instance_groups/0/jobs/0/name"), Value: "capi"},
+				{Path: MustNewPointerFromString("/instance_groups/0/jobs/1/name"), Value: "nats"},
+				{Path: MustNewPointerFromString("/instance_groups/1/name"), Value: "worker"},
+				{Path: MustNewPointerFromString("/instance_groups/1/jobs/0/name"), Value: "worker"},
+			}))
+
+			res, err = FindAllOp{Path: MustNewPointerFromString("/instance_groups/1/**/name=worker/name")}.Resolve(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]FoundValue{
+				{Path: MustNewPointerFromString("/instance_groups/1/jobs/0/name"), Value: "worker"},
+			}))
+		})
+
+		It("returns values matched by recursive descent below keys of any type", func() {
+			doc := map[interface{}]interface{}{
+				80:   map[interface{}]interface{}{"a": 1},
+				true: []interface{}{map[interface{}]interface{}{"a": 2}},
+			}
+
+			res, err := FindAllOp{Path: MustNewPointerFromString("/**/a")}.Resolve(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]FoundValue{
+				{Path: MustNewPointerFromString("/!!int 80/a"), Value: 1},
+				{Path: MustNewPointerFromString("/!!bool true/0/a"), Value: 2},
+			}))
+		})
+
+		It("includes value itself and does not duplicate values for repeated recursive descent", func() {
+			res, err := FindAllOp{Path: MustNewPointerFromString("/instance_groups/0/jobs/**/**/name")}.Resolve(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]FoundValue{
+				{Path: MustNewPointerFromString("/instance_groups/0/jobs/0/name"), Value: "capi"},
+				{Path: MustNewPointerFromString("/instance_groups/0/jobs/1/name"), Value: "nats"},
+			}))
+
+			res, err = FindAllOp{Path: MustNewPointerFromString("/instance_groups/0/jobs/0/**")}.Resolve(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]FoundValue{
+				{Path: MustNewPointerFromString("/instance_groups/0/jobs/0"), Value: map[interface{}]interface{}{"name": "capi"}},
+				{Path: MustNewPointerFromString("/instance_groups/0/jobs/0/name"), Value: "capi"},
+			}))
+		})
+
+		It("returns an error if recursive descent does not match anything unless it's optional", func() {
+			_, err := FindAllOp{Path: MustNewPointerFromString("/**/tls/ca")}.Resolve(doc)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected to find at least one value matching path '/**/tls/ca'"))
+
+			res, err := FindAllOp{Path: MustNewPointerFromString("/**?/tls/ca")}.Resolve(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]FoundValue{}))
+		})
+
+		It("returns an error if tokens before recursive descent cannot be resolved", func() {
+			_, err := FindAllOp{Path: MustNewPointerFromString("/releases/**/name")}.Resolve(doc)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(
+				"Expected to find a map key 'releases' for path '/releases' (found map keys: 'instance_groups')"))
+		})
+
+		It("returns array items in range as a single value with concrete range", func() {
+			res, err := FindAllOp{Path: MustNewPointerFromString("/instance_groups/*/jobs/-1..")}.Resolve(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]FoundValue{
+				{Path: MustNewPointerFromString("/instance_groups/0/jobs/1..2"), Value: []interface{}{map[interface{}]interface{}{"name": "nats"}}},
+				{Path: MustNewPointerFromString("/instance_groups/1/jobs/0..1"), Value: []interface{}{map[interface{}]interface{}{"name": "worker"}}},
+			}))
+		})
+
+		It("returns an error for after last index token", func() {
+			_, err := FindAllOp{Path: MustNewPointerFromString("/instance_groups/-")}.Resolve(doc)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(
+				"Expected not to find after last index token in path '/instance_groups/-' (not supported in find operations)"))
+		})
+	})
+
+	Describe("Apply", func() {
+		It("returns an array of found values", func() {
+			res, err := FindAllOp{Path: MustNewPointerFromString("/instance_groups/*/name")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{"api", "worker"}))
+
+			res, err = FindAllOp{Path: MustNewPointerFromString("/instance_groups/*/env?")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{}))
+		})
+	})
+})
@@ -15,12 +15,20 @@ func (op FindOp) Apply(doc interface{}) (interface{}, error) {
 		return doc, nil
 	}
 
+	// Paths with wildcards result in an array of all found values
+	if isMultiMatch(op.Path) {
+		return FindAllOp{Path: op.Path}.Apply(doc)
+	}
+
 	obj := doc
+	mapType, _ := docMapType(doc)
 
 	for i, token := range tokens[1:] {
 		isLast := i == len(tokens)-2
 		currPath := NewPointer(tokens[:i+2])
 
+		token = afterLastIndexKey(token, obj, isLast)
+
 		switch typedToken := token.(type) {
 		case IndexToken:
 			typedObj, ok := obj.([]interface{})
@@ -43,36 +51,50 @@ func (op FindOp) Apply(doc interface{}) (interface{}, error) {
 			errMsg := "Expected not to find after last index token in path '%s' (not supported in find operations)"
 			return nil, fmt.Errorf(errMsg, op.Path)
 
-		case MatchingIndexToken:
+		case RangeToken:
 			typedObj, ok := obj.([]interface{})
 			if !ok {
 				return nil, NewOpArrayMismatchTypeErr(currPath, obj)
 			}
 
-			var idxs []int
+			if !isLast {
+				return nil, fmt.Errorf("Expected range token to be last in path '%s'", op.Path)
+			}
 
-			for itemIdx, item := range typedObj {
-				typedItem, ok := item.(map[interface{}]interface{})
-				if ok {
-					if typedItem[typedToken.Key] == typedToken.Value {
-						idxs = append(idxs, itemIdx)
-					}
-				}
+			start, end, err := ArrayRange{Start: typedToken.Start, End: typedToken.End, ToEnd: typedToken.ToEnd, Array: typedObj, Path: currPath}.Concrete()
+			if err != nil {
+				return nil, err
 			}
 
+			return append([]interface{}{}, typedObj[start:end]...), nil
+
+		case MatchingIndexToken:
+			typedObj, ok := obj.([]interface{})
+			if !ok {
+				return nil, NewOpArrayMismatchTypeErr(currPath, obj)
+			}
+
+			idxs := matchingIndexes(typedToken, typedObj)
+
 			if typedToken.Optional && len(idxs) == 0 {
-				// todo /blah=foo?:after, modifiers
-				obj = map[interface{}]interface{}{typedToken.Key: typedToken.Value}
+				// Position of the missing item does not affect its value
+				var err error
+
+				obj, err = newMatchingItem(mapType, typedToken, currPath)
+				if err != nil {
+					return nil, err
+				}
 
 				if isLast {
 					return obj, nil
 				}
 			} else {
-				if len(idxs) != 1 {
-					return nil, OpMultipleMatchingIndexErr{currPath, idxs}
+				idxs, modifiers, err := selectMatchingIndexes(typedToken, idxs, currPath)
+				if err != nil {
+					return nil, err
 				}
 
-				idx, err := ArrayIndex{Index: idxs[0], Modifiers: typedToken.Modifiers, Array: typedObj, Path: currPath}.Concrete()
+				idx, err := ArrayIndex{Index: idxs[0], Modifiers: modifiers, Array: typedObj, Path: currPath}.Concrete()
 				if err != nil {
 					return nil, err
 				}
@@ -85,20 +107,20 @@ func (op FindOp) Apply(doc interface{}) (interface{}, error) {
 			}
 
 		case KeyToken:
-			typedObj, ok := obj.(map[interface{}]interface{})
+			typedObj, ok := newGenericMap(obj)
 			if !ok {
 				return nil, NewOpMapMismatchTypeErr(currPath, obj)
 			}
 
 			var found bool
 
-			obj, found = typedObj[typedToken.Key]
+			obj, found = typedObj.Get(typedToken.mapKey())
 			if !found && !typedToken.Optional {
-				return nil, OpMissingMapKeyErr{typedToken.Key, currPath, typedObj}
+				return nil, newOpMissingMapKeyErr(typedToken.Key, currPath, typedObj)
 			}
 
 			if isLast {
-				return typedObj[typedToken.Key], nil
+				return obj, nil
 			} else {
 				if !found {
 					// Determine what type of value to create based on next token
@@ -106,7 +128,7 @@ func (op FindOp) Apply(doc interface{}) (interface{}, error) {
 					case MatchingIndexToken:
 						obj = []interface{}{}
 					case KeyToken:
-						obj = map[interface{}]interface{}{}
+						obj = typedObj.Type().New().Obj()
 					default:
 						errMsg := "Expected to find key or matching index token at path '%s'"
 						return nil, fmt.Errorf(errMsg, NewPointer(tokens[:i+3]))
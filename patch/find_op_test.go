@@ -90,6 +90,63 @@ var _ = Describe("FindOp.Apply", func() {
 		})
 	})
 
+	Describe("array range", func() {
+		It("finds array items in range", func() {
+			doc := []interface{}{1, 2, 3, 4, 5}
+
+			res, err := FindOp{Path: MustNewPointerFromString("/1..3")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{2, 3}))
+
+			res, err = FindOp{Path: MustNewPointerFromString("/..2")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{1, 2}))
+
+			res, err = FindOp{Path: MustNewPointerFromString("/-2..")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{4, 5}))
+
+			res, err = FindOp{Path: MustNewPointerFromString("/2..2")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{}))
+		})
+
+		It("finds array items in range from an array that is inside a map", func() {
+			doc := map[interface{}]interface{}{
+				"abc": []interface{}{1, 2, 3},
+			}
+
+			res, err := FindOp{Path: MustNewPointerFromString("/abc/1..")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{2, 3}))
+		})
+
+		It("returns an error if range is out of bounds", func() {
+			_, err := FindOp{Path: MustNewPointerFromString("/1..4")}.Apply([]interface{}{1, 2, 3})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(
+				"Expected to find array index '4' but found array of length '3' for path '/1..4'"))
+
+			_, err = FindOp{Path: MustNewPointerFromString("/2..1")}.Apply([]interface{}{1, 2, 3})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(
+				"Expected range start '2' to not be after range end '1' for path '/2..1'"))
+		})
+
+		It("returns an error if range token is not last", func() {
+			_, err := FindOp{Path: MustNewPointerFromString("/1..2/0")}.Apply([]interface{}{1, 2, 3})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected range token to be last in path '/1..2/0'"))
+		})
+
+		It("returns an error if it's not an array being accessed", func() {
+			_, err := FindOp{Path: MustNewPointerFromString("/1..2")}.Apply(map[interface{}]interface{}{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(
+				"Expected to find an array at path '/1..2' but found 'map[interface {}]interface {}'"))
+		})
+	})
+
 	Describe("array with after last item", func() {
 		It("returns an error as after-last-index tokens are not supported", func() {
 			_, err := FindOp{Path: MustNewPointerFromString("/-")}.Apply([]interface{}{})
@@ -118,6 +175,42 @@ var _ = Describe("FindOp.Apply", func() {
 				"Expected not to find after last index token in path '/abc/-' (not supported in find operations)"))
 		})
 
+		It("finds map key '-' in the middle of a path", func() {
+			doc := map[interface{}]interface{}{
+				"-": map[interface{}]interface{}{"key": "val"},
+			}
+
+			res, err := FindOp{Path: MustNewPointerFromString("/-/key")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal("val"))
+		})
+
+		It("finds quoted map key '-'", func() {
+			doc := map[interface{}]interface{}{
+				"-": map[interface{}]interface{}{"key": "val"},
+			}
+
+			res, err := FindOp{Path: MustNewPointerFromString("/'-'/key")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal("val"))
+		})
+
+		It("returns an error if after last index token in the middle of a path is applied to an array", func() {
+			doc := map[interface{}]interface{}{
+				"items": []interface{}{map[interface{}]interface{}{"name": "api"}},
+			}
+
+			_, err := FindOp{Path: MustNewPointerFromString("/items/-/name")}.Apply(doc)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(
+				"Expected not to find after last index token in path '/items/-/name' (not supported in find operations)"))
+
+			_, err = FindOp{Path: MustNewPointerFromString("/*/-/name")}.Apply(doc)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(
+				"Expected not to find after last index token in path '/*/-/name' (not supported in find operations)"))
+		})
+
 		It("returns an error if after last index token is not last", func() {
 			ptr := NewPointer([]Token{RootToken{}, AfterLastIndexToken{}, KeyToken{}})
 
@@ -189,6 +282,209 @@ var _ = Describe("FindOp.Apply", func() {
 				"Expected to find exactly one matching array item for path '/key=val' but found 2"))
 		})
 
+		It("finds selected array item if multiple items found", func() {
+			doc := []interface{}{
+				map[interface{}]interface{}{"key": "val", "idx": 0},
+				map[interface{}]interface{}{"key": "val2", "idx": 1},
+				map[interface{}]interface{}{"key": "val", "idx": 2},
+				map[interface{}]interface{}{"key": "val", "idx": 3},
+			}
+
+			res, err := FindOp{Path: MustNewPointerFromString("/key=val:first/idx")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(0))
+
+			res, err = FindOp{Path: MustNewPointerFromString("/key=val:last/idx")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(3))
+
+			res, err = FindOp{Path: MustNewPointerFromString("/key=val:nth=1/idx")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(2))
+
+			res, err = FindOp{Path: MustNewPointerFromString("/key=val:nth=-2:prev/idx")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(1))
+
+			res, err = FindOp{Path: MustNewPointerFromString("/key=val:all/idx")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{0, 2, 3}))
+
+			res, err = FindOp{Path: MustNewPointerFromString("/key=val3:all/idx")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{}))
+		})
+
+		It("returns an error if selected item is not found", func() {
+			doc := []interface{}{
+				map[interface{}]interface{}{"key": "val"},
+				map[interface{}]interface{}{"key": "val"},
+			}
+
+			_, err := FindOp{Path: MustNewPointerFromString("/key=val:nth=2")}.Apply(doc)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(
+				"Expected to find matching array item '2' for path '/key=val:nth=2' but found 2"))
+
+			_, err = FindOp{Path: MustNewPointerFromString("/key=val2:first")}.Apply(doc)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(
+				"Expected to find matching array item '0' for path '/key=val2:first' but found 0"))
+		})
+
+		It("finds array item matching all predicates", func() {
+			doc := []interface{}{
+				map[interface{}]interface{}{"name": "api", "az": "z1", "idx": 0},
+				map[interface{}]interface{}{"name": "api", "az": "z2", "idx": 1},
+				map[interface{}]interface{}{"name": "db", "az": "z2", "idx": 2},
+			}
+
+			res, err := FindOp{Path: MustNewPointerFromString("/name=api,az=z2/idx")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(1))
+		})
+
+		It("finds array item matching nested values", func() {
+			doc := []interface{}{
+				map[interface{}]interface{}{
+					"release":    map[interface{}]interface{}{"name": "capi"},
+					"properties": map[interface{}]interface{}{"tls": map[interface{}]interface{}{"enabled": "true"}},
+					"idx":        0,
+				},
+				map[interface{}]interface{}{
+					"release":      map[interface{}]interface{}{"name": "uaa"},
+					"release.name": "capi",
+					"idx":          1,
+				},
+			}
+
+			res, err := FindOp{Path: MustNewPointerFromString("/properties~1tls~1enabled=true/idx")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(0))
+
+			res, err = FindOp{Path: MustNewPointerFromString("/release.name=capi:all/idx")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{0, 1}))
+
+			res, err = FindOp{Path: MustNewPointerFromString("/release.name=uaa:all/idx")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{}))
+		})
+
+		It("finds array item by typed value", func() {
+			doc := []interface{}{
+				map[interface{}]interface{}{"instances": "0", "enabled": "true", "idx": 0},
+				map[interface{}]interface{}{"instances": 0, "enabled": true, "idx": 1},
+				map[interface{}]interface{}{"instances": 1.0, "enabled": nil, "idx": 2},
+			}
+
+			res, err := FindOp{Path: MustNewPointerFromString("/instances=0:all/idx")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{0, 1}))
+
+			res, err = FindOp{Path: MustNewPointerFromString("/instances=!!str 0/idx")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(0))
+
+			res, err = FindOp{Path: MustNewPointerFromString("/instances=!!int 0/idx")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(1))
+
+			res, err = FindOp{Path: MustNewPointerFromString("/instances=1/idx")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(2))
+
+			res, err = FindOp{Path: MustNewPointerFromString("/enabled=true:all/idx")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{0, 1}))
+
+			res, err = FindOp{Path: MustNewPointerFromString("/enabled=null/idx")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(2))
+		})
+
+		It("does not match missing keys with empty value", func() {
+			doc := []interface{}{
+				map[interface{}]interface{}{"idx": 0},
+				map[interface{}]interface{}{"name": "", "idx": 1},
+			}
+
+			res, err := FindOp{Path: MustNewPointerFromString("/name=/idx")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(1))
+		})
+
+		It("finds array items using predicate operators", func() {
+			doc := []interface{}{
+				map[interface{}]interface{}{"name": "errand-smoke", "instances": 0, "idx": 0},
+				map[interface{}]interface{}{"name": "api", "instances": 2, "env": "env", "idx": 1},
+				map[interface{}]interface{}{"name": "api-worker", "instances": "3", "idx": 2},
+				map[interface{}]interface{}{"name": 5, "idx": 3},
+			}
+
+			expectIdxs := func(path string, idxs ...interface{}) {
+				res, err := FindOp{Path: MustNewPointerFromString(path + ":all/idx")}.Apply(doc)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(res).To(Equal(append([]interface{}{}, idxs...)), path)
+			}
+
+			expectIdxs("/name!=api", 0, 2, 3)
+			expectIdxs("/name~=^api", 1, 2)
+			expectIdxs("/name~=^[0-9]+$")
+			expectIdxs("/name^=errand-", 0)
+			expectIdxs("/name$=-worker", 2)
+			expectIdxs("/instances>0", 1, 2)
+			expectIdxs("/instances>=0,instances<3", 0, 1)
+			expectIdxs("/instances<=0", 0)
+			expectIdxs("/name>4", 3)
+			expectIdxs("/env=*", 1)
+			expectIdxs("/env!=*", 0, 2, 3)
+		})
+
+		It("matches regular expressions against every array item", func() {
+			doc := []interface{}{
+				map[interface{}]interface{}{"name": "api-1"},
+				map[interface{}]interface{}{"name": "web"},
+				map[interface{}]interface{}{"name": "api-2"},
+			}
+
+			res, err := FindOp{Path: MustNewPointerFromString("/name~=^api-[0-9]$:all/name")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{"api-1", "api-2"}))
+		})
+
+		It("returns an error if missing item cannot be created without equality predicates", func() {
+			_, err := FindOp{Path: MustNewPointerFromString("/name!=api,instances>0?")}.Apply([]interface{}{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(
+				"Expected to find at least one equality predicate to create missing array item for path '/name!=api,instances>0?'"))
+		})
+
+		It("finds scalar array item by value", func() {
+			doc := []interface{}{"z1", 80, "z2", "z2"}
+
+			res, err := FindOp{Path: MustNewPointerFromString("/=z1")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal("z1"))
+
+			res, err = FindOp{Path: MustNewPointerFromString("/=80")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(80))
+
+			res, err = FindOp{Path: MustNewPointerFromString("/^=z:all")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{"z1", "z2", "z2"}))
+
+			res, err = FindOp{Path: MustNewPointerFromString("/=z3?")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal("z3"))
+
+			_, err = FindOp{Path: MustNewPointerFromString("/=z2")}.Apply(doc)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(
+				"Expected to find exactly one matching array item for path '/=z2' but found 2"))
+		})
+
 		It("finds array item even if not all items are maps", func() {
 			doc := []interface{}{
 				3,
@@ -242,6 +538,21 @@ var _ = Describe("FindOp.Apply", func() {
 			Expect(res).To(BeNil())
 		})
 
+		It("finds existing or missing matching item regardless of anchor modifier", func() {
+			doc := []interface{}{
+				map[interface{}]interface{}{"name": "foo", "release": "foo"},
+				map[interface{}]interface{}{"name": "bar"},
+			}
+
+			res, err := FindOp{Path: MustNewPointerFromString("/name=foo?:before=name=bar/release")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal("foo"))
+
+			res, err = FindOp{Path: MustNewPointerFromString("/name=baz?:after=name=bar")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{"name": "baz"}))
+		})
+
 		It("finds nested missing matching item if it does not exist", func() {
 			doc := []interface{}{map[interface{}]interface{}{"xyz": "xyz"}}
 
@@ -275,6 +586,37 @@ var _ = Describe("FindOp.Apply", func() {
 			Expect(res).To(Equal("abc"))
 		})
 
+		It("finds non-string map key by its tag", func() {
+			doc := map[interface{}]interface{}{
+				80:     "http",
+				"80":   "string",
+				true:   "yes",
+				1.5:    "float",
+				"name": "abc",
+			}
+
+			res, err := FindOp{Path: MustNewPointerFromString("/!!int 80")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal("http"))
+
+			res, err = FindOp{Path: MustNewPointerFromString("/'80'")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal("string"))
+
+			res, err = FindOp{Path: MustNewPointerFromString("/!!bool true")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal("yes"))
+
+			res, err = FindOp{Path: MustNewPointerFromString("/!!float 1.5")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal("float"))
+
+			_, err = FindOp{Path: MustNewPointerFromString("/!!int 81")}.Apply(doc)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(
+				"Expected to find a map key '81' for path '/!!int 81' (found map keys: '80', 'name')"))
+		})
+
 		It("finds nested map key", func() {
 			doc := map[interface{}]interface{}{
 				"abc": map[interface{}]interface{}{
@@ -376,4 +718,21 @@ var _ = Describe("FindOp.Apply", func() {
 				"Expected to find a map at path '/abc' but found '[]interface {}'"))
 		})
 	})
+
+	Describe("wildcard", func() {
+		It("returns an array of all matched values", func() {
+			doc := []interface{}{
+				map[interface{}]interface{}{"name": "a", "instances": 1},
+				map[interface{}]interface{}{"name": "b", "instances": 2},
+			}
+
+			res, err := FindOp{Path: MustNewPointerFromString("/*/instances")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{1, 2}))
+
+			res, err = FindOp{Path: MustNewPointerFromString("/*")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(doc))
+		})
+	})
 })
@@ -0,0 +1,154 @@
+package patch_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/stuart-pollock/go-patch/patch"
+)
+
+var _ = Describe("JSON compatibility", func() {
+	var doc interface{}
+
+	BeforeEach(func() {
+		str := `{
+  "name": "dep",
+  "instance_groups": [
+    {"name": "api", "instances": 1, "jobs": [{"name": "capi"}]},
+    {"name": "worker", "instances": 2}
+  ]
+}`
+
+		err := json.Unmarshal([]byte(str), &doc)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	toJSON := func(obj interface{}) string {
+		bs, err := json.Marshal(obj)
+		Expect(err).ToNot(HaveOccurred())
+		return string(bs)
+	}
+
+	It("finds values in maps with string keys", func() {
+		res, err := FindOp{Path: MustNewPointerFromString("/instance_groups/name=api/jobs/0/name")}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal("capi"))
+
+		res, err = FindOp{Path: MustNewPointerFromString("/instance_groups/name=api/env?/bosh")}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(BeNil())
+	})
+
+	It("replaces values keeping map type for new values and intermediate maps", func() {
+		res, err := Ops{
+			ReplaceOp{Path: MustNewPointerFromString("/instance_groups/name=api/env?/bosh/password"), Value: "pass"},
+			ReplaceOp{Path: MustNewPointerFromString("/instance_groups/name=errand?/jobs/-"), Value: map[interface{}]interface{}{"name": "smoke"}},
+			ReplaceOp{Path: MustNewPointerFromString("/instance_groups/name=worker/instances"), Value: 3},
+		}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(toJSON(res)).To(MatchJSON(`{
+  "name": "dep",
+  "instance_groups": [
+    {"name": "api", "instances": 1, "jobs": [{"name": "capi"}], "env": {"bosh": {"password": "pass"}}},
+    {"name": "worker", "instances": 3},
+    {"name": "errand", "jobs": [{"name": "smoke"}]}
+  ]
+}`))
+
+		igs := res.(map[string]interface{})["instance_groups"].([]interface{})
+		Expect(igs[0].(map[string]interface{})["env"]).To(BeAssignableToTypeOf(map[string]interface{}{}))
+		Expect(igs[2].(map[string]interface{})["jobs"].([]interface{})[0]).To(BeAssignableToTypeOf(map[string]interface{}{}))
+	})
+
+	It("keeps map type of value when replacing entire document", func() {
+		res, err := ReplaceOp{Path: MustNewPointerFromString(""), Value: doc}.Apply(nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(BeAssignableToTypeOf(map[string]interface{}{}))
+	})
+
+	It("removes values", func() {
+		res, err := Ops{
+			RemoveOp{Path: MustNewPointerFromString("/instance_groups/name=api/jobs")},
+			RemoveOp{Path: MustNewPointerFromString("/instance_groups/name=worker")},
+			RemoveOp{Path: MustNewPointerFromString("/missing?")},
+		}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(toJSON(res)).To(MatchJSON(`{"name": "dep", "instance_groups": [{"name": "api", "instances": 1}]}`))
+	})
+
+	It("tests values comparing numbers by value and maps of either type", func() {
+		_, err := Ops{
+			TestOp{Path: MustNewPointerFromString("/instance_groups/name=worker/instances"), Value: 2},
+			TestOp{Path: MustNewPointerFromString("/instance_groups/name=api/jobs/0"), Value: map[interface{}]interface{}{"name": "capi"}},
+			TestOp{Path: MustNewPointerFromString("/instance_groups/name=api/env"), Absent: true},
+		}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = TestOp{Path: MustNewPointerFromString("/instance_groups/name=worker/instances"), Value: 3}.Apply(doc)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Found value does not match expected value"))
+	})
+
+	It("applies merge patch", func() {
+		res, err := MergePatchOp{
+			Path:  MustNewPointerFromString("/instance_groups/name=api"),
+			Value: map[interface{}]interface{}{"jobs": nil, "env": map[interface{}]interface{}{"a": "b"}},
+		}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res.(map[string]interface{})["instance_groups"].([]interface{})[0]).To(Equal(map[string]interface{}{
+			"name":      "api",
+			"instances": float64(1),
+			"env":       map[string]interface{}{"a": "b"},
+		}))
+	})
+
+	It("returns missing key errors listing string keys", func() {
+		_, err := FindOp{Path: MustNewPointerFromString("/instance_groups/name=api/missing")}.Apply(doc)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal(
+			"Expected to find a map key 'missing' for path '/instance_groups/name=api/missing' (found map keys: 'instances', 'jobs', 'name')"))
+
+		typedErr, ok := err.(OpMissingMapKeyErr)
+		Expect(ok).To(BeTrue())
+		Expect(typedErr.Obj).To(BeNil())
+		Expect(typedErr.JSONObj).To(HaveKeyWithValue("name", "api"))
+
+		_, err = FindOp{Path: MustNewPointerFromString("/missing")}.Apply(map[interface{}]interface{}{"name": "api"})
+		Expect(err).To(HaveOccurred())
+
+		typedErr, ok = err.(OpMissingMapKeyErr)
+		Expect(ok).To(BeTrue())
+		Expect(typedErr.Obj).To(Equal(map[interface{}]interface{}{"name": "api"}))
+		Expect(typedErr.JSONObj).To(BeNil())
+	})
+
+	It("returns error if string keyed map is not found", func() {
+		_, err := FindOp{Path: MustNewPointerFromString("/name/key")}.Apply(doc)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find a map at path '/name/key' but found 'string'"))
+	})
+
+	It("calculates diff between documents", func() {
+		var right interface{}
+
+		err := json.Unmarshal([]byte(`{
+  "name": "dep2",
+  "instance_groups": [{"name": "api", "instances": 1, "jobs": [{"name": "capi"}]}]
+}`), &right)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(toJSON(Diff{Left: doc, Right: right}.CalculateMergePatch())).To(MatchJSON(`{
+  "name": "dep2",
+  "instance_groups": [{"name": "api", "instances": 1, "jobs": [{"name": "capi"}]}]
+}`))
+
+		res, err := Diff{Left: doc, Right: right}.Calculate().Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(right))
+	})
+})
@@ -0,0 +1,95 @@
+package patch
+
+import (
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// sortedKeys returns map keys in a deterministic order
+func sortedKeys(obj genericMap) []interface{} {
+	keys := obj.Keys()
+	sortYAMLKeys(keys)
+	return keys
+}
+
+// sortYAMLKeys orders map keys by their YAML representation;
+// each key is marshaled once rather than on every comparison
+func sortYAMLKeys(keys []interface{}) {
+	sortKeys := make([]string, len(keys))
+
+	for i, key := range keys {
+		bs, _ := yaml.Marshal(key)
+		sortKeys[i] = string(bs)
+	}
+
+	sort.Stable(yamlKeySorter{keys, sortKeys})
+}
+
+type yamlKeySorter struct {
+	keys     []interface{}
+	sortKeys []string
+}
+
+func (s yamlKeySorter) Len() int           { return len(s.keys) }
+func (s yamlKeySorter) Less(i, j int) bool { return s.sortKeys[i] < s.sortKeys[j] }
+
+func (s yamlKeySorter) Swap(i, j int) {
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+	s.sortKeys[i], s.sortKeys[j] = s.sortKeys[j], s.sortKeys[i]
+}
+
+// matchingKeys returns sorted string keys of the map that match the glob pattern;
+// keys of other types (ex: 80, true) are only matched by a wildcard
+func matchingKeys(token KeyGlobToken, obj genericMap) []string {
+	var keys []string
+
+	for _, key := range sortedKeys(obj) {
+		if keyStr, ok := key.(string); ok && globMatch(token.Pattern, keyStr) {
+			keys = append(keys, keyStr)
+		}
+	}
+
+	return keys
+}
+
+// globMatch checks if string matches pattern where '*' matches any sequence of characters
+func globMatch(pattern, str string) bool {
+	pieces := strings.Split(pattern, "*")
+
+	if !strings.HasPrefix(str, pieces[0]) {
+		return false
+	}
+
+	str = str[len(pieces[0]):]
+
+	if len(pieces) == 1 {
+		return len(str) == 0
+	}
+
+	for _, piece := range pieces[1 : len(pieces)-1] {
+		idx := strings.Index(str, piece)
+		if idx == -1 {
+			return false
+		}
+		str = str[idx+len(piece):]
+	}
+
+	return strings.HasSuffix(str, pieces[len(pieces)-1])
+}
+
+// globsOverlap checks if there is a string that matches both glob patterns
+// (literal parts before the first and after the last '*' must be compatible)
+func globsOverlap(left, right string) bool {
+	leftPieces := strings.Split(left, "*")
+	rightPieces := strings.Split(right, "*")
+
+	leftPrefix, rightPrefix := leftPieces[0], rightPieces[0]
+	leftSuffix, rightSuffix := leftPieces[len(leftPieces)-1], rightPieces[len(rightPieces)-1]
+
+	prefixesOk := strings.HasPrefix(leftPrefix, rightPrefix) || strings.HasPrefix(rightPrefix, leftPrefix)
+	suffixesOk := strings.HasSuffix(leftSuffix, rightSuffix) || strings.HasSuffix(rightSuffix, leftSuffix)
+
+	return prefixesOk && suffixesOk
+}
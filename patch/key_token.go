@@ -0,0 +1,75 @@
+package patch
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// newKeyToken returns token that refers to given map key
+func newKeyToken(key interface{}) KeyToken {
+	if keyStr, ok := key.(string); ok {
+		return KeyToken{Key: keyStr}
+	}
+
+	var tag string
+
+	switch key.(type) {
+	case int, int64, uint64:
+		tag = "!!int"
+	case float64:
+		tag = "!!float"
+	case bool:
+		tag = "!!bool"
+	case nil:
+		tag = "!!null"
+	default:
+		return KeyToken{Key: fmt.Sprintf("%v", key)}
+	}
+
+	bytes, err := yaml.Marshal(key)
+	if err != nil {
+		return KeyToken{Key: fmt.Sprintf("%v", key)}
+	}
+
+	return KeyToken{Key: strings.TrimSuffix(string(bytes), "\n"), Tag: tag}
+}
+
+// mapKey returns map key that token refers to
+func (t KeyToken) mapKey() interface{} {
+	if len(t.Tag) == 0 {
+		return t.Key
+	}
+
+	key, err := typedMapKey(t.Tag, t.Key)
+	if err != nil {
+		return t.Key
+	}
+
+	return key
+}
+
+// afterLastIndexKey returns key token '-' if after last index token
+// in the middle of a path is applied to a map (ex: /-/key);
+// otherwise token is returned as is
+func afterLastIndexKey(token Token, obj interface{}, isLast bool) Token {
+	if _, ok := token.(AfterLastIndexToken); ok && !isLast {
+		if _, ok := newGenericMap(obj); ok {
+			return KeyToken{Key: "-"}
+		}
+	}
+	return token
+}
+
+// typedMapKey parses key as YAML with given tag (ex: '!!int 80')
+func typedMapKey(tag, key string) (interface{}, error) {
+	var typedKey interface{}
+
+	err := yaml.Unmarshal([]byte(tag+" "+key), &typedKey)
+	if err != nil {
+		return nil, fmt.Errorf("Expected to find valid '%s' map key but found '%s': %s", tag, key, err)
+	}
+
+	return typedKey, nil
+}
@@ -0,0 +1,329 @@
+package patch
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// mapType identifies map representation used by a document:
+// yaml.v2 decodes maps as map[interface{}]interface{} while
+// encoding/json (and yaml.v3) decode them as map[string]interface{}
+type mapType int
+
+const (
+	yamlMapType mapType = iota
+	jsonMapType
+)
+
+// docMapType returns map type of the first map found in given objects
+func docMapType(objs ...interface{}) (mapType, bool) {
+	for _, obj := range objs {
+		switch typedObj := obj.(type) {
+		case map[interface{}]interface{}:
+			return yamlMapType, true
+
+		case map[string]interface{}:
+			return jsonMapType, true
+
+		case []interface{}:
+			if t, found := docMapType(typedObj...); found {
+				return t, true
+			}
+		}
+	}
+
+	return yamlMapType, false
+}
+
+// New returns an empty map of this type
+func (t mapType) New() genericMap {
+	if t == jsonMapType {
+		return genericMap{typ: jsonMapType, json: map[string]interface{}{}}
+	}
+	return genericMap{yaml: map[interface{}]interface{}{}}
+}
+
+// Convert recursively converts maps of other type into maps of this type.
+// Maps and arrays are updated in place hence it should only be used on cloned values.
+func (t mapType) Convert(val interface{}) interface{} {
+	switch typedVal := val.(type) {
+	case map[interface{}]interface{}:
+		if t == yamlMapType {
+			for k, v := range typedVal {
+				typedVal[k] = t.Convert(v)
+			}
+			return typedVal
+		}
+
+		result := map[string]interface{}{}
+		for k, v := range typedVal {
+			result[jsonMapKey(k)] = t.Convert(v)
+		}
+		return result
+
+	case map[string]interface{}:
+		if t == jsonMapType {
+			for k, v := range typedVal {
+				typedVal[k] = t.Convert(v)
+			}
+			return typedVal
+		}
+
+		result := map[interface{}]interface{}{}
+		for k, v := range typedVal {
+			result[k] = t.Convert(v)
+		}
+		return result
+
+	case []interface{}:
+		for i, v := range typedVal {
+			typedVal[i] = t.Convert(v)
+		}
+		return typedVal
+
+	default:
+		return val
+	}
+}
+
+func jsonMapKey(key interface{}) string {
+	if keyStr, ok := key.(string); ok {
+		return keyStr
+	}
+	return fmt.Sprintf("%v", key)
+}
+
+// jsonValue converts maps with interface{} keys into maps with string keys
+// so that value could be serialized with encoding/json
+func jsonValue(val interface{}) interface{} {
+	switch typedVal := val.(type) {
+	case map[interface{}]interface{}:
+		result := map[string]interface{}{}
+		for k, v := range typedVal {
+			result[fmt.Sprintf("%v", k)] = jsonValue(v)
+		}
+		return result
+
+	case map[string]interface{}:
+		result := map[string]interface{}{}
+		for k, v := range typedVal {
+			result[k] = jsonValue(v)
+		}
+		return result
+
+	case []interface{}:
+		result := []interface{}{}
+		for _, v := range typedVal {
+			result = append(result, jsonValue(v))
+		}
+		return result
+
+	default:
+		return val
+	}
+}
+
+// genericMap provides common access to both supported map types
+type genericMap struct {
+	typ  mapType
+	yaml map[interface{}]interface{}
+	json map[string]interface{}
+}
+
+func newGenericMap(obj interface{}) (genericMap, bool) {
+	switch typedObj := obj.(type) {
+	case map[interface{}]interface{}:
+		return genericMap{yaml: typedObj}, true
+	case map[string]interface{}:
+		return genericMap{typ: jsonMapType, json: typedObj}, true
+	default:
+		return genericMap{}, false
+	}
+}
+
+func (m genericMap) Type() mapType {
+	return m.typ
+}
+
+// Obj returns underlying map
+func (m genericMap) Obj() interface{} {
+	if m.typ == jsonMapType {
+		return m.json
+	}
+	return m.yaml
+}
+
+func (m genericMap) Len() int {
+	if m.typ == jsonMapType {
+		return len(m.json)
+	}
+	return len(m.yaml)
+}
+
+func (m genericMap) Get(key interface{}) (interface{}, bool) {
+	if m.typ == jsonMapType {
+		keyStr, ok := key.(string)
+		if !ok {
+			return nil, false
+		}
+		val, found := m.json[keyStr]
+		return val, found
+	}
+	val, found := m.yaml[key]
+	return val, found
+}
+
+func (m genericMap) Set(key, val interface{}) {
+	if m.typ == jsonMapType {
+		m.json[jsonMapKey(key)] = val
+	} else {
+		m.yaml[key] = val
+	}
+}
+
+func (m genericMap) Delete(key interface{}) {
+	if m.typ == jsonMapType {
+		if keyStr, ok := key.(string); ok {
+			delete(m.json, keyStr)
+		}
+	} else {
+		delete(m.yaml, key)
+	}
+}
+
+// Keys returns map keys in no particular order
+func (m genericMap) Keys() []interface{} {
+	var keys []interface{}
+	if m.typ == jsonMapType {
+		for k := range m.json {
+			keys = append(keys, k)
+		}
+	} else {
+		for k := range m.yaml {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// isBasicValue checks if value only consists of types produced by YAML and JSON decoders
+func isBasicValue(obj interface{}) bool {
+	switch typedObj := obj.(type) {
+	case nil, string, bool, int, float64:
+		return true
+
+	case map[interface{}]interface{}:
+		for k, v := range typedObj {
+			if !isBasicValue(k) || !isBasicValue(v) {
+				return false
+			}
+		}
+		return true
+
+	case map[string]interface{}:
+		for _, v := range typedObj {
+			if !isBasicValue(v) {
+				return false
+			}
+		}
+		return true
+
+	case []interface{}:
+		for _, v := range typedObj {
+			if !isBasicValue(v) {
+				return false
+			}
+		}
+		return true
+
+	default:
+		return false
+	}
+}
+
+// copyValue makes a deep copy of maps and arrays keeping their types
+func copyValue(obj interface{}) interface{} {
+	switch typedObj := obj.(type) {
+	case map[interface{}]interface{}:
+		result := map[interface{}]interface{}{}
+		for k, v := range typedObj {
+			result[k] = copyValue(v)
+		}
+		return result
+
+	case map[string]interface{}:
+		result := map[string]interface{}{}
+		for k, v := range typedObj {
+			result[k] = copyValue(v)
+		}
+		return result
+
+	case []interface{}:
+		result := []interface{}{}
+		for _, v := range typedObj {
+			result = append(result, copyValue(v))
+		}
+		return result
+
+	default:
+		return obj
+	}
+}
+
+// valuesEqual compares values deeply treating both map types as equivalent
+// and comparing numbers by value (encoding/json decodes all numbers as float64)
+func valuesEqual(left, right interface{}) bool {
+	if leftMap, ok := newGenericMap(left); ok {
+		rightMap, ok := newGenericMap(right)
+		if !ok || leftMap.Len() != rightMap.Len() {
+			return false
+		}
+		for _, k := range leftMap.Keys() {
+			if rightMap.Type() == jsonMapType {
+				k = jsonMapKey(k)
+			}
+			leftVal, _ := leftMap.Get(k)
+			rightVal, found := rightMap.Get(k)
+			if !found || !valuesEqual(leftVal, rightVal) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if leftAry, ok := left.([]interface{}); ok {
+		rightAry, ok := right.([]interface{})
+		if !ok || len(leftAry) != len(rightAry) {
+			return false
+		}
+		for i := range leftAry {
+			if !valuesEqual(leftAry[i], rightAry[i]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if leftNum, ok := numberValue(left); ok {
+		if rightNum, ok := numberValue(right); ok {
+			return leftNum == rightNum
+		}
+	}
+
+	return reflect.DeepEqual(left, right)
+}
+
+func numberValue(val interface{}) (float64, bool) {
+	switch typedVal := val.(type) {
+	case int:
+		return float64(typedVal), true
+	case int64:
+		return float64(typedVal), true
+	case uint64:
+		return float64(typedVal), true
+	case float64:
+		return typedVal, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,413 @@
+package patch
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// matchingIndexes returns indexes of array items that match all token's predicates
+func matchingIndexes(token MatchingIndexToken, array []interface{}) []int {
+	var idxs []int
+
+	preds := compiledPredicates(token)
+
+	for itemIdx, item := range array {
+		matched := true
+
+		for _, pred := range preds {
+			if !pred.matches(item) {
+				matched = false
+				break
+			}
+		}
+
+		if matched {
+			idxs = append(idxs, itemIdx)
+		}
+	}
+
+	return idxs
+}
+
+// compiledPredicate is a predicate with its value parsed once per token
+// rather than for every array item
+type compiledPredicate struct {
+	MatchingPredicate
+
+	typedVal interface{}    // value as YAML would parse it (see matchingValue)
+	tagged   bool           // value is explicitly tagged (ex: !!str 0)
+	re       *regexp.Regexp // compiled value of '~=' predicate
+}
+
+// compiledPredicates returns token's predicates with parsed values
+func compiledPredicates(token MatchingIndexToken) []compiledPredicate {
+	var preds []compiledPredicate
+
+	for _, pred := range matchingPredicates(token) {
+		compiled := compiledPredicate{MatchingPredicate: pred}
+
+		switch pred.Operator {
+		case "", "!=":
+			compiled.typedVal, compiled.tagged = matchingValue(pred.Value)
+		case "~=":
+			compiled.re, _ = regexp.Compile(pred.Value)
+		}
+
+		preds = append(preds, compiled)
+	}
+
+	return preds
+}
+
+// matches checks if array item satisfies predicate
+func (p compiledPredicate) matches(item interface{}) bool {
+	val, found := nestedValue(item, p.Key)
+
+	// Empty key refers to the item itself in arrays of scalars (ex: /azs/=z1)
+	if _, isMap := newGenericMap(item); !isMap && len(p.Key) == 0 {
+		val, found = item, true
+	}
+
+	switch p.Operator {
+	case "":
+		if p.Value == "*" {
+			return found
+		}
+		return found && p.matchesValue(val)
+
+	case "!=":
+		if p.Value == "*" {
+			return !found
+		}
+		return !found || !p.matchesValue(val)
+
+	case "~=":
+		strVal, ok := val.(string)
+		return ok && p.re != nil && p.re.MatchString(strVal)
+
+	case "^=":
+		strVal, ok := val.(string)
+		return ok && strings.HasPrefix(strVal, p.Value)
+
+	case "$=":
+		strVal, ok := val.(string)
+		return ok && strings.HasSuffix(strVal, p.Value)
+
+	case "<", "<=", ">", ">=":
+		numVal, ok := numberValue(val)
+		if !ok {
+			strVal, isStr := val.(string)
+			if !isStr {
+				return false
+			}
+			var err error
+			numVal, err = strconv.ParseFloat(strVal, 64)
+			if err != nil {
+				return false
+			}
+		}
+
+		predVal, err := strconv.ParseFloat(p.Value, 64)
+		if err != nil {
+			return false
+		}
+
+		switch p.Operator {
+		case "<":
+			return numVal < predVal
+		case "<=":
+			return numVal <= predVal
+		case ">":
+			return numVal > predVal
+		default:
+			return numVal >= predVal
+		}
+
+	default:
+		return false
+	}
+}
+
+// validate checks that predicate operator is known and its value could be used with it
+func (p MatchingPredicate) validate() error {
+	switch p.Operator {
+	case "", "!=", "^=", "$=":
+		return nil
+
+	case "~=":
+		_, err := regexp.Compile(p.Value)
+		if err != nil {
+			return fmt.Errorf("Expected to find valid regular expression in predicate '%s~=%s': %s", p.Key, p.Value, err)
+		}
+		return nil
+
+	case "<", "<=", ">", ">=":
+		_, err := strconv.ParseFloat(p.Value, 64)
+		if err != nil {
+			return fmt.Errorf("Expected to find number in predicate '%s%s%s' but found '%s'", p.Key, p.Operator, p.Value, p.Value)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("Expected to find one of the following predicate operators: '=', '!=', '~=', '^=', '$=', '<', '<=', '>', or '>=' but found '%s'", p.Operator)
+	}
+}
+
+// matchesValue checks if value equals to predicate value either as a string
+// or as a value YAML would parse it to (ex: '0' matches integer 0, 'true' matches bool);
+// explicitly tagged predicate values (ex: '!!str 0') only match values of the same type
+func (p compiledPredicate) matchesValue(val interface{}) bool {
+	if strVal, ok := val.(string); ok && !p.tagged && strVal == p.Value {
+		return true
+	}
+
+	return valuesEqual(val, p.typedVal)
+}
+
+// matchingValue returns predicate value as YAML would parse it if it's explicitly tagged
+// or if it's a non-string scalar; otherwise predicate value is returned as is
+func matchingValue(predVal string) (interface{}, bool) {
+	var typedVal interface{}
+
+	err := yaml.Unmarshal([]byte(predVal), &typedVal)
+	if err != nil {
+		return predVal, false
+	}
+
+	tagged := strings.HasPrefix(predVal, "!!")
+
+	switch typedVal.(type) {
+	case map[interface{}]interface{}, []interface{}:
+		return predVal, false
+	case string:
+		if !tagged {
+			return predVal, false
+		}
+	}
+
+	return typedVal, tagged
+}
+
+// createdMatchingValue returns value set on created array item;
+// only explicitly tagged predicate values are typed (ex: '!!int 1'),
+// otherwise predicate value is used as is (ex: '1.10', 'on')
+func createdMatchingValue(predVal string) interface{} {
+	if typedVal, tagged := matchingValue(predVal); tagged {
+		return typedVal
+	}
+	return predVal
+}
+
+// exactMatchingValue returns predicate value that only matches
+// given scalar value of the same type (ex: '!!int 80')
+func exactMatchingValue(val interface{}) (string, error) {
+	var tag string
+
+	switch val.(type) {
+	case string:
+		tag = "!!str"
+	case int, int64, uint64:
+		tag = "!!int"
+	case float64:
+		tag = "!!float"
+	case bool:
+		tag = "!!bool"
+	default:
+		return "", fmt.Errorf("Expected scalar value but found '%T'", val)
+	}
+
+	bytes, err := yaml.Marshal(val)
+	if err != nil {
+		return "", err
+	}
+
+	return tag + " " + strings.TrimSuffix(string(bytes), "\n"), nil
+}
+
+// matchingPredicates returns token's key and value followed by additional predicates
+func matchingPredicates(token MatchingIndexToken) []MatchingPredicate {
+	preds := []MatchingPredicate{{Key: token.Key, Value: token.Value, Operator: token.Operator}}
+	return append(preds, token.Predicates...)
+}
+
+// nestedValue looks up map value by the key that may refer to nested maps
+// via '.' or '/' separators (ex: release.name); map keys that include
+// separators themselves are preferred (ex: key 'release.name' is used if present)
+func nestedValue(obj interface{}, key string) (interface{}, bool) {
+	typedObj, ok := newGenericMap(obj)
+	if !ok {
+		return nil, false
+	}
+
+	if val, found := typedObj.Get(key); found {
+		return val, true
+	}
+
+	for i := len(key) - 1; i >= 0; i-- {
+		if key[i] != '.' && key[i] != '/' {
+			continue
+		}
+
+		if val, found := typedObj.Get(key[:i]); found {
+			if nestedVal, found := nestedValue(val, key[i+1:]); found {
+				return nestedVal, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// newMatchingItem creates map with values of token's equality predicates;
+// at least one equality predicate is required so that item is not empty
+func newMatchingItem(mapType mapType, token MatchingIndexToken, path Pointer) (interface{}, error) {
+	// Scalar item is its own value (ex: /azs/=z1?)
+	if isScalarMatching(token) {
+		return createdMatchingValue(token.Value), nil
+	}
+
+	item := mapType.New()
+	var found bool
+
+	for _, pred := range matchingPredicates(token) {
+		// Only equality predicates determine item's values
+		if len(pred.Operator) > 0 || pred.Value == "*" {
+			continue
+		}
+
+		found = true
+
+		obj := item
+		pieces := strings.Split(strings.Replace(pred.Key, ".", "/", -1), "/")
+
+		for _, piece := range pieces[:len(pieces)-1] {
+			nestedObj, found := obj.Get(piece)
+			if typedNestedObj, ok := newGenericMap(nestedObj); found && ok {
+				obj = typedNestedObj
+				continue
+			}
+
+			typedNestedObj := mapType.New()
+			obj.Set(piece, typedNestedObj.Obj())
+			obj = typedNestedObj
+		}
+
+		obj.Set(pieces[len(pieces)-1], createdMatchingValue(pred.Value))
+	}
+
+	if !found {
+		return nil, fmt.Errorf("Expected to find at least one equality predicate to create missing array item for path '%s'", path)
+	}
+
+	return item.Obj(), nil
+}
+
+// selectMatchingIndexes chooses matched indexes according to token's selection modifier
+// and returns them with the rest of token's modifiers. Without selection modifier
+// exactly one match is expected; 'all' modifier allows any number of matches.
+func selectMatchingIndexes(token MatchingIndexToken, idxs []int, path Pointer) ([]int, []Modifier, error) {
+	var selection Modifier
+	var modifiers []Modifier
+
+	for _, modifier := range token.Modifiers {
+		switch modifier.(type) {
+		case AllModifier, FirstModifier, LastModifier, NthModifier:
+			selection = modifier
+		case BeforeMatchingModifier, AfterMatchingModifier:
+			// only used when adding missing item
+		default:
+			modifiers = append(modifiers, modifier)
+		}
+	}
+
+	var nth int
+
+	switch typedSelection := selection.(type) {
+	case nil:
+		if len(idxs) != 1 {
+			return nil, nil, OpMultipleMatchingIndexErr{path, idxs}
+		}
+		return idxs, modifiers, nil
+
+	case AllModifier:
+		return idxs, modifiers, nil
+
+	case FirstModifier:
+		nth = 0
+
+	case LastModifier:
+		nth = -1
+
+	case NthModifier:
+		nth = typedSelection.N
+	}
+
+	if nth >= len(idxs) || (-nth)-1 >= len(idxs) {
+		return nil, nil, OpMissingMatchingIndexErr{nth, path, idxs}
+	}
+
+	if nth < 0 {
+		nth = len(idxs) + nth
+	}
+
+	return []int{idxs[nth]}, modifiers, nil
+}
+
+// missingMatchingPosition returns position at which array item is added
+// for an optional matching index token that does not match anything:
+// next to the array item matching 'before=' or 'after=' modifier (at the end if nothing matches it),
+// at the beginning for 'before' modifier or at the end otherwise
+func missingMatchingPosition(token MatchingIndexToken, array []interface{}, path Pointer) (int, error) {
+	pos := len(array)
+
+	for _, modifier := range token.Modifiers {
+		var preds []MatchingPredicate
+		var offset int
+
+		switch typedModifier := modifier.(type) {
+		case BeforeModifier:
+			pos = 0
+			continue
+		case BeforeMatchingModifier:
+			preds = typedModifier.Predicates
+		case AfterMatchingModifier:
+			preds, offset = typedModifier.Predicates, 1
+		default:
+			continue
+		}
+
+		anchorToken := MatchingIndexToken{Key: preds[0].Key, Value: preds[0].Value, Operator: preds[0].Operator, Predicates: preds[1:]}
+
+		idxs := matchingIndexes(anchorToken, array)
+
+		switch len(idxs) {
+		case 0:
+			return len(array), nil
+		case 1:
+			return idxs[0] + offset, nil
+		default:
+			return 0, OpMultipleMatchingIndexErr{path, idxs}
+		}
+	}
+
+	return pos, nil
+}
+
+// isAllMatching checks if token selects all matching array items
+func isAllMatching(token MatchingIndexToken) bool {
+	for _, modifier := range token.Modifiers {
+		if _, ok := modifier.(AllModifier); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isScalarMatching checks if token only matches scalar array items by their value
+func isScalarMatching(token MatchingIndexToken) bool {
+	return len(token.Key) == 0 && len(token.Operator) == 0 && token.Value != "*" && len(token.Predicates) == 0
+}
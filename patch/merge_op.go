@@ -0,0 +1,168 @@
+package patch
+
+import (
+	"fmt"
+)
+
+// MergeConflict determines how MergeOp resolves values that are set
+// both in the document and in the merged value but cannot be merged
+// (anything other than two maps)
+type MergeConflict string
+
+const (
+	MergeConflictOverwrite MergeConflict = "overwrite" // merged value wins (default)
+	MergeConflictKeep      MergeConflict = "keep"      // document value wins
+	MergeConflictError     MergeConflict = "error"     // merge fails
+)
+
+// MergeOp deep merges map value into the map found at the path.
+// Missing map keys in the path (and null values) are created as if
+// they were optional; nested maps are merged recursively
+// and other values that differ are resolved by the conflict policy.
+type MergeOp struct {
+	Path     Pointer
+	Value    interface{} // will be cloned using yaml library
+	Conflict MergeConflict
+}
+
+func (op MergeOp) Apply(doc interface{}) (interface{}, error) {
+	err := op.checkConflict()
+	if err != nil {
+		return nil, err
+	}
+
+	typedValue, ok := newGenericMap(op.Value)
+	if !ok {
+		return nil, fmt.Errorf("Expected value to be a map to merge at path '%s' but found '%T'", op.Path, op.Value)
+	}
+
+	if isMultiMatch(op.Path) {
+		return op.applyAll(doc, typedValue)
+	}
+
+	path := op.optionalPath()
+
+	target, err := FindOp{Path: path}.Apply(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	merged, err := op.merge(target, typedValue, op.Path.Tokens())
+	if err != nil {
+		return nil, err
+	}
+
+	return ReplaceOp{Path: path, Value: merged}.Apply(doc)
+}
+
+func (op MergeOp) checkConflict() error {
+	switch op.Conflict {
+	case "", MergeConflictOverwrite, MergeConflictKeep, MergeConflictError:
+		return nil
+	default:
+		errMsg := "Expected merge conflict policy to be one of 'overwrite', 'keep' or 'error' but found '%s'"
+		return fmt.Errorf(errMsg, op.Conflict)
+	}
+}
+
+// applyAll merges value into every map matched by the path
+func (op MergeOp) applyAll(doc interface{}, value genericMap) (interface{}, error) {
+	foundVals, err := FindAllOp{Path: op.Path}.Resolve(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, foundVal := range foundVals {
+		merged, err := op.merge(foundVal.Value, value, foundVal.Path.Tokens())
+		if err != nil {
+			return nil, err
+		}
+
+		doc, err = ReplaceOp{Path: foundVal.Path, Value: merged}.Apply(doc)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return doc, nil
+}
+
+// optionalPath marks all map keys as optional so that missing parents are created
+func (op MergeOp) optionalPath() Pointer {
+	var tokens []Token
+
+	for _, token := range op.Path.Tokens() {
+		if typedToken, ok := token.(KeyToken); ok {
+			typedToken.Optional = true
+			token = typedToken
+		}
+		tokens = append(tokens, token)
+	}
+
+	return NewPointer(tokens)
+}
+
+func (op MergeOp) merge(target interface{}, value genericMap, tokens []Token) (interface{}, error) {
+	if target == nil {
+		return value.Obj(), nil
+	}
+
+	typedTarget, ok := newGenericMap(target)
+	if !ok {
+		return nil, NewOpMapMismatchTypeErr(NewPointer(tokens), target)
+	}
+
+	result := typedTarget.Type().New()
+
+	// Shallow copy is enough since replace operation clones resulting value
+	for _, k := range typedTarget.Keys() {
+		v, _ := typedTarget.Get(k)
+		result.Set(k, v)
+	}
+
+	// Sorted keys make conflict errors deterministic
+	for _, k := range sortedKeys(value) {
+		v, _ := value.Get(k)
+		keyTokens := append(append([]Token{}, tokens...), newKeyToken(k))
+
+		existing, _ := result.Get(k)
+
+		if typedVal, ok := newGenericMap(v); ok {
+			if _, ok := newGenericMap(existing); ok || existing == nil {
+				merged, err := op.merge(existing, typedVal, keyTokens)
+				if err != nil {
+					return nil, err
+				}
+				result.Set(k, merged)
+				continue
+			}
+		}
+
+		resolved, err := op.Conflict.resolve(existing, v, NewPointer(keyTokens))
+		if err != nil {
+			return nil, err
+		}
+
+		result.Set(k, resolved)
+	}
+
+	return result.Obj(), nil
+}
+
+// resolve returns value that is kept when merged value is set
+// in place of existing value that cannot be merged with it
+func (c MergeConflict) resolve(existing, val interface{}, path Pointer) (interface{}, error) {
+	if existing == nil || valuesEqual(existing, val) {
+		return val, nil
+	}
+
+	switch c {
+	case MergeConflictKeep:
+		return existing, nil
+	case MergeConflictError:
+		errMsg := "Expected to find no conflicting value at path '%s' but found '%v' (merged value '%v')"
+		return nil, fmt.Errorf(errMsg, path, existing, val)
+	default:
+		return val, nil
+	}
+}
@@ -0,0 +1,197 @@
+package patch_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v2"
+
+	. "github.com/stuart-pollock/go-patch/patch"
+)
+
+var _ = Describe("MergeOp.Apply", func() {
+	fromYAML := func(str string) interface{} {
+		var val interface{}
+		err := yaml.Unmarshal([]byte(str), &val)
+		Expect(err).ToNot(HaveOccurred())
+		return val
+	}
+
+	var doc interface{}
+
+	BeforeEach(func() {
+		doc = fromYAML(`
+instance_groups:
+- name: api
+  jobs:
+  - name: capi
+    properties:
+      tls: {enabled: false, ca: old}
+      ports: [80]
+`)
+	})
+
+	It("deep merges map into the map found at the path", func() {
+		res, err := MergeOp{
+			Path:  MustNewPointerFromString("/instance_groups/name=api/jobs/name=capi/properties"),
+			Value: fromYAML(`{tls: {enabled: true, cert: new}, ports: [443], legacy: null}`),
+		}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(fromYAML(`
+instance_groups:
+- name: api
+  jobs:
+  - name: capi
+    properties:
+      tls: {enabled: true, ca: old, cert: new}
+      ports: [443]
+      legacy: null
+`)))
+	})
+
+	It("keeps document values on conflict if requested", func() {
+		res, err := MergeOp{
+			Path:     MustNewPointerFromString("/instance_groups/name=api/jobs/name=capi/properties"),
+			Value:    fromYAML(`{tls: {enabled: true, cert: new}, ports: [443]}`),
+			Conflict: MergeConflictKeep,
+		}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(fromYAML(`
+instance_groups:
+- name: api
+  jobs:
+  - name: capi
+    properties:
+      tls: {enabled: false, ca: old, cert: new}
+      ports: [80]
+`)))
+	})
+
+	It("returns an error on conflict if requested", func() {
+		_, err := MergeOp{
+			Path:     MustNewPointerFromString("/instance_groups/name=api/jobs/name=capi/properties"),
+			Value:    fromYAML(`{tls: {enabled: true, cert: new}, ports: [80]}`),
+			Conflict: MergeConflictError,
+		}.Apply(doc)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find no conflicting value at path " +
+			"'/instance_groups/name=api/jobs/name=capi/properties/tls/enabled' but found 'false' (merged value 'true')"))
+
+		res, err := MergeOp{
+			Path:     MustNewPointerFromString("/instance_groups/name=api/jobs/name=capi/properties"),
+			Value:    fromYAML(`{tls: {enabled: false, cert: new}, ports: [80]}`),
+			Conflict: MergeConflictError,
+		}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(fromYAML(`
+instance_groups:
+- name: api
+  jobs:
+  - name: capi
+    properties:
+      tls: {enabled: false, ca: old, cert: new}
+      ports: [80]
+`)))
+	})
+
+	It("treats a map replacing other value as a conflict", func() {
+		_, err := MergeOp{
+			Path:     MustNewPointerFromString("/instance_groups/name=api/jobs/name=capi/properties"),
+			Value:    fromYAML(`{ports: {http: 80}}`),
+			Conflict: MergeConflictError,
+		}.Apply(doc)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find no conflicting value at path " +
+			"'/instance_groups/name=api/jobs/name=capi/properties/ports' but found '[80]' (merged value 'map[http:80]')"))
+
+		res, err := MergeOp{
+			Path:  MustNewPointerFromString("/instance_groups/name=api/jobs/name=capi/properties"),
+			Value: fromYAML(`{ports: {http: 80}}`),
+		}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(fromYAML(`
+instance_groups:
+- name: api
+  jobs:
+  - name: capi
+    properties:
+      tls: {enabled: false, ca: old}
+      ports: {http: 80}
+`)))
+	})
+
+	It("creates missing parents", func() {
+		res, err := MergeOp{
+			Path:  MustNewPointerFromString("/instance_groups/name=api/jobs/name=capi/properties/db/tls"),
+			Value: fromYAML(`{enabled: true}`),
+		}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(fromYAML(`
+instance_groups:
+- name: api
+  jobs:
+  - name: capi
+    properties:
+      tls: {enabled: false, ca: old}
+      ports: [80]
+      db: {tls: {enabled: true}}
+`)))
+
+		res, err = MergeOp{
+			Path:  MustNewPointerFromString("/a/b"),
+			Value: fromYAML(`{c: {d: 1}}`),
+		}.Apply(fromYAML(`{a: {b: null, e: 2}}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(fromYAML(`{a: {b: {c: {d: 1}}, e: 2}}`)))
+	})
+
+	It("merges value into every map matched by wildcard", func() {
+		res, err := MergeOp{
+			Path:  MustNewPointerFromString("/instance_groups/*/env"),
+			Value: fromYAML(`{a: b}`),
+		}.Apply(fromYAML(`
+instance_groups:
+- env: {c: d}
+- env: null
+`))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(fromYAML(`
+instance_groups:
+- env: {a: b, c: d}
+- env: {a: b}
+`)))
+	})
+
+	It("does not modify merged value", func() {
+		value := fromYAML(`{a: {b: 1}}`)
+
+		res, err := MergeOp{Path: MustNewPointerFromString(""), Value: value}.Apply(fromYAML(`{a: {c: 2}}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(fromYAML(`{a: {b: 1, c: 2}}`)))
+
+		Expect(value).To(Equal(fromYAML(`{a: {b: 1}}`)))
+	})
+
+	It("returns an error if value is not a map", func() {
+		_, err := MergeOp{Path: MustNewPointerFromString("/a"), Value: 1}.Apply(fromYAML(`{a: {}}`))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected value to be a map to merge at path '/a' but found 'int'"))
+	})
+
+	It("returns an error if value at the path is not a map", func() {
+		_, err := MergeOp{Path: MustNewPointerFromString("/a"), Value: fromYAML(`{b: 1}`)}.Apply(fromYAML(`{a: [1]}`))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find a map at path '/a' but found '[]interface {}'"))
+	})
+
+	It("returns an error if conflict policy is unknown", func() {
+		_, err := MergeOp{Path: MustNewPointerFromString("/a"), Value: fromYAML(`{b: 1}`), Conflict: "other"}.Apply(fromYAML(`{a: {}}`))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected merge conflict policy to be one of 'overwrite', 'keep' or 'error' but found 'other'"))
+	})
+})
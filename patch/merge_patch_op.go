@@ -0,0 +1,79 @@
+package patch
+
+// MergePatchOp applies RFC 7386 JSON Merge Patch (https://tools.ietf.org/html/rfc7386)
+// to the value found at the path: maps are merged recursively,
+// null values remove keys and all other values replace existing ones.
+type MergePatchOp struct {
+	Path  Pointer
+	Value interface{} // will be cloned using yaml library
+}
+
+func (op MergePatchOp) Apply(doc interface{}) (interface{}, error) {
+	if isMultiMatch(op.Path) {
+		return op.applyAll(doc)
+	}
+
+	target, err := FindOp{Path: op.Path}.Apply(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err = ReplaceOp{Path: op.Path, Value: op.merge(target, op.Value)}.Apply(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// applyAll merges patch into every value matched by the path
+func (op MergePatchOp) applyAll(doc interface{}) (interface{}, error) {
+	foundVals, err := FindAllOp{Path: op.Path}.Resolve(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, foundVal := range foundVals {
+		doc, err = ReplaceOp{Path: foundVal.Path, Value: op.merge(foundVal.Value, op.Value)}.Apply(doc)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return doc, nil
+}
+
+func (op MergePatchOp) merge(target, patch interface{}) interface{} {
+	typedPatch, ok := newGenericMap(patch)
+	if !ok {
+		return patch
+	}
+
+	// Keep target's map type; replace operation converts new maps to document's map type
+	typedTarget, ok := newGenericMap(target)
+	if !ok {
+		typedTarget = typedPatch
+	}
+
+	result := typedTarget.Type().New()
+
+	// Shallow copy is enough since replace operation clones resulting value
+	if ok {
+		for _, k := range typedTarget.Keys() {
+			v, _ := typedTarget.Get(k)
+			result.Set(k, v)
+		}
+	}
+
+	for _, k := range typedPatch.Keys() {
+		v, _ := typedPatch.Get(k)
+		if v == nil {
+			result.Delete(k)
+		} else {
+			resultVal, _ := result.Get(k)
+			result.Set(k, op.merge(resultVal, v))
+		}
+	}
+
+	return result.Obj()
+}
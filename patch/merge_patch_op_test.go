@@ -0,0 +1,117 @@
+package patch_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v2"
+
+	. "github.com/stuart-pollock/go-patch/patch"
+)
+
+var _ = Describe("MergePatchOp.Apply", func() {
+	fromYAML := func(str string) interface{} {
+		var val interface{}
+		err := yaml.Unmarshal([]byte(str), &val)
+		Expect(err).ToNot(HaveOccurred())
+		return val
+	}
+
+	It("follows RFC 7386 examples", func() {
+		examples := [][]string{
+			{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+			{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+			{`{"a":"b"}`, `{"a":null}`, `{}`},
+			{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+			{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+			{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+			{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+			{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+			{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+			{`{"a":"b"}`, `["c"]`, `["c"]`},
+			{`{"a":"foo"}`, `null`, `null`},
+			{`{"a":"foo"}`, `"bar"`, `"bar"`},
+			{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+			{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+			{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+		}
+
+		for _, example := range examples {
+			res, err := MergePatchOp{Path: MustNewPointerFromString(""), Value: fromYAML(example[1])}.Apply(fromYAML(example[0]))
+			Expect(err).ToNot(HaveOccurred())
+
+			if expected := fromYAML(example[2]); expected == nil {
+				Expect(res).To(BeNil())
+			} else {
+				Expect(res).To(Equal(expected), "%#v", example)
+			}
+		}
+	})
+
+	It("merges into the value found at the path", func() {
+		doc := fromYAML(`
+instance_groups:
+- name: api
+  jobs:
+  - name: capi
+    properties:
+      tls: {enabled: false, ca: old}
+      legacy: true
+`)
+
+		res, err := MergePatchOp{
+			Path:  MustNewPointerFromString("/instance_groups/name=api/jobs/name=capi/properties"),
+			Value: fromYAML(`{tls: {enabled: true, ca: null, cert: new}, legacy: null, port: 443}`),
+		}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(fromYAML(`
+instance_groups:
+- name: api
+  jobs:
+  - name: capi
+    properties:
+      tls: {enabled: true, cert: new}
+      port: 443
+`)))
+	})
+
+	It("creates missing values for optional paths", func() {
+		res, err := MergePatchOp{
+			Path:  MustNewPointerFromString("/a/b?/c"),
+			Value: fromYAML(`{d: 1, e: null}`),
+		}.Apply(fromYAML(`{a: {}}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(fromYAML(`{a: {b: {c: {d: 1}}}}`)))
+	})
+
+	It("does not modify patch value", func() {
+		patch := fromYAML(`{a: {b: 1}}`)
+
+		res, err := MergePatchOp{Path: MustNewPointerFromString(""), Value: patch}.Apply(fromYAML(`{a: {c: 2}}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(fromYAML(`{a: {b: 1, c: 2}}`)))
+
+		Expect(patch).To(Equal(fromYAML(`{a: {b: 1}}`)))
+	})
+
+	It("returns an error if path does not exist", func() {
+		_, err := MergePatchOp{Path: MustNewPointerFromString("/b"), Value: 1}.Apply(fromYAML(`{a: 1}`))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find a map key 'b' for path '/b' (found map keys: 'a')"))
+	})
+
+	It("merges patch into every value matched by wildcard", func() {
+		res, err := MergePatchOp{
+			Path:  MustNewPointerFromString("/*/env"),
+			Value: map[interface{}]interface{}{"a": "b"},
+		}.Apply([]interface{}{
+			map[interface{}]interface{}{"env": map[interface{}]interface{}{"c": "d"}},
+			map[interface{}]interface{}{"env": nil},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal([]interface{}{
+			map[interface{}]interface{}{"env": map[interface{}]interface{}{"a": "b", "c": "d"}},
+			map[interface{}]interface{}{"env": map[interface{}]interface{}{"a": "b"}},
+		}))
+	})
+})
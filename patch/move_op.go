@@ -1,25 +1,293 @@
 package patch
 
+import (
+	"fmt"
+)
+
+// MoveOp moves value found at from path to path.
+// Path is resolved against the document with moved value replaced by a marker
+// so that indices, matching index tokens and modifiers refer to the same
+// array items as before the move; the moved value itself cannot be matched
+// (ex: path /items/name=a:after with from /items/name=a does not match anything).
 type MoveOp struct {
 	Path Pointer
 	From Pointer
 }
 
+// movedMarker temporarily takes place of a moved value
+// until the value is added to the new location
+type movedMarker struct {
+	Index int
+}
+
+func moveOpWithinErr(path, from Pointer) error {
+	return fmt.Errorf("Expected path '%s' to not be within moved value at path '%s'", path, from)
+}
+
 func (op MoveOp) Apply(doc interface{}) (interface{}, error) {
-	val, err := FindOp{Path: op.From}.Apply(doc)
+	val, origVals, err := op.markSources(doc)
 	if err != nil {
 		return nil, err
 	}
 
-	doc, err = ReplaceOp{Path: op.Path, Value: val}.Apply(doc)
+	newDoc, err := ReplaceOp{Path: op.Path, Value: val}.Apply(doc)
 	if err != nil {
+		op.restoreSources(doc, origVals)
 		return nil, err
 	}
 
-	doc, err = RemoveOp{Path: op.From}.Apply(doc)
+	// Markers are not found if moved value replaced itself
+	markerPaths := movedMarkerPaths(newDoc, []Token{RootToken{}})
+
+	// Remove values starting from the end so that array indices stay valid
+	for i := len(markerPaths) - 1; i >= 0; i-- {
+		newDoc, err = RemoveOp{Path: markerPaths[i]}.Apply(newDoc)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return newDoc, nil
+}
+
+// markSources replaces moved values with markers in place
+// returning moved value and original values indexed by marker index
+func (op MoveOp) markSources(doc interface{}) (interface{}, []interface{}, error) {
+	if len(op.From.Tokens()) == 1 {
+		return nil, nil, fmt.Errorf("Cannot remove entire document")
+	}
+
+	val, err := FindOp{Path: op.From}.Apply(doc)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	foundVals, err := FindAllOp{Path: op.From}.Resolve(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Recursive descent token may resolve to the document itself
+	for _, foundVal := range foundVals {
+		if len(foundVal.Path.Tokens()) == 1 {
+			return nil, nil, fmt.Errorf("Cannot remove entire document")
+		}
+	}
+
+	var sources []FoundValue
+
+	// Values nested in other moved values are moved together with them
+	for _, foundVal := range foundVals {
+		nested := false
+		for _, source := range sources {
+			if isWithinPath(foundVal.Path, source.Path) {
+				nested = true
+				break
+			}
+		}
+		if !nested {
+			sources = append(sources, foundVal)
+		}
+	}
+
+	err = op.checkNotWithinSources(doc, sources)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return doc, nil
+	var origVals []interface{}
+
+	for _, source := range sources {
+		var marker interface{}
+
+		if origItems, ok := source.Value.([]interface{}); ok && isRangePath(source.Path) {
+			markers := []interface{}{}
+			for _, origItem := range origItems {
+				markers = append(markers, movedMarker{Index: len(origVals)})
+				origVals = append(origVals, origItem)
+			}
+			marker = markers
+		} else {
+			marker = movedMarker{Index: len(origVals)}
+			origVals = append(origVals, source.Value)
+		}
+
+		err = setFoundValue(doc, source.Path, marker)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return val, origVals, nil
+}
+
+// checkNotWithinSources makes sure that none of the parents of path
+// resolve to one of the moved values
+func (op MoveOp) checkNotWithinSources(doc interface{}, sources []FoundValue) error {
+	tokens := op.Path.Tokens()
+
+	for i := 2; i < len(tokens); i++ {
+		parentVals, err := FindAllOp{Path: NewPointer(tokens[:i])}.Resolve(doc)
+		if err != nil {
+			// Replace operation reports errors for paths that cannot be resolved
+			return nil
+		}
+
+		for _, parentVal := range parentVals {
+			for _, source := range sources {
+				if isWithinPath(parentVal.Path, source.Path) {
+					return moveOpWithinErr(op.Path, op.From)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// restoreSources puts original values back in place of markers
+func (op MoveOp) restoreSources(doc interface{}, origVals []interface{}) {
+	var restore func(interface{}) interface{}
+
+	restore = func(obj interface{}) interface{} {
+		if marker, ok := obj.(movedMarker); ok {
+			return origVals[marker.Index]
+		}
+
+		if typedObj, ok := newGenericMap(obj); ok {
+			for _, key := range typedObj.Keys() {
+				val, _ := typedObj.Get(key)
+				typedObj.Set(key, restore(val))
+			}
+		} else if typedObj, ok := obj.([]interface{}); ok {
+			for idx, val := range typedObj {
+				typedObj[idx] = restore(val)
+			}
+		}
+
+		return obj
+	}
+
+	restore(doc)
+}
+
+// movedMarkerPaths returns paths of all markers in document order
+func movedMarkerPaths(obj interface{}, tokens []Token) []Pointer {
+	if _, ok := obj.(movedMarker); ok {
+		return []Pointer{NewPointer(tokens)}
+	}
+
+	var paths []Pointer
+
+	if typedObj, ok := newGenericMap(obj); ok {
+		for _, key := range typedObj.Keys() {
+			val, _ := typedObj.Get(key)
+			nextTokens := append(append([]Token{}, tokens...), newKeyToken(key))
+			paths = append(paths, movedMarkerPaths(val, nextTokens)...)
+		}
+	} else if typedObj, ok := obj.([]interface{}); ok {
+		for idx, val := range typedObj {
+			nextTokens := append(append([]Token{}, tokens...), IndexToken{Index: idx})
+			paths = append(paths, movedMarkerPaths(val, nextTokens)...)
+		}
+	}
+
+	return paths
+}
+
+// setFoundValue sets value in place at concrete path returned by FindAllOp
+// (array items covered by a range token are set from the given array)
+func setFoundValue(doc interface{}, path Pointer, val interface{}) error {
+	tokens := path.Tokens()
+
+	parent, err := FindOp{Path: NewPointer(tokens[:len(tokens)-1])}.Apply(doc)
+	if err != nil {
+		return err
+	}
+
+	switch typedToken := tokens[len(tokens)-1].(type) {
+	case IndexToken:
+		typedParent, ok := parent.([]interface{})
+		if !ok {
+			return NewOpArrayMismatchTypeErr(path, parent)
+		}
+		typedParent[typedToken.Index] = val
+
+	case RangeToken:
+		typedParent, ok := parent.([]interface{})
+		if !ok {
+			return NewOpArrayMismatchTypeErr(path, parent)
+		}
+		copy(typedParent[typedToken.Start:typedToken.End], val.([]interface{}))
+
+	case KeyToken:
+		typedParent, ok := newGenericMap(parent)
+		if !ok {
+			return NewOpMapMismatchTypeErr(path, parent)
+		}
+		typedParent.Set(typedToken.mapKey(), val)
+
+	default:
+		return OpUnexpectedTokenErr{typedToken, path}
+	}
+
+	return nil
+}
+
+// isWithinPath checks if concrete path is the same as or nested in concrete parent path
+// (array items covered by a range token are nested in it)
+func isWithinPath(path, parent Pointer) bool {
+	tokens := path.Tokens()
+	parentTokens := parent.Tokens()
+
+	if len(tokens) < len(parentTokens) {
+		return false
+	}
+
+	for i, parentToken := range parentTokens {
+		switch typedParent := parentToken.(type) {
+		case RootToken:
+			if _, ok := tokens[i].(RootToken); !ok {
+				return false
+			}
+
+		case IndexToken:
+			typedToken, ok := tokens[i].(IndexToken)
+			if !ok || typedToken.Index != typedParent.Index {
+				return false
+			}
+
+		case RangeToken:
+			switch typedToken := tokens[i].(type) {
+			case IndexToken:
+				if typedToken.Index < typedParent.Start || typedToken.Index >= typedParent.End {
+					return false
+				}
+			case RangeToken:
+				if typedToken != typedParent {
+					return false
+				}
+			default:
+				return false
+			}
+
+		case KeyToken:
+			typedToken, ok := tokens[i].(KeyToken)
+			if !ok || typedToken.Key != typedParent.Key || typedToken.Tag != typedParent.Tag {
+				return false
+			}
+
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// isRangePath checks if path ends with range token
+func isRangePath(path Pointer) bool {
+	tokens := path.Tokens()
+	_, ok := tokens[len(tokens)-1].(RangeToken)
+	return ok
 }
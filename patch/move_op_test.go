@@ -46,6 +46,20 @@ var _ = Describe("MoveOp.Apply", func() {
 		Expect(err.Error()).To(Equal("Cannot remove entire document"))
 	})
 
+	It("returns an error if from resolves to the entire document", func() {
+		doc := map[interface{}]interface{}{
+			"xyz": "xyz",
+		}
+
+		_, err := MoveOp{Path: MustNewPointerFromString("/z?"), From: MustNewPointerFromString("/**")}.Apply(doc)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Cannot remove entire document"))
+
+		Expect(doc).To(Equal(map[interface{}]interface{}{
+			"xyz": "xyz",
+		}))
+	})
+
 	It("moves matching item within a map", func() {
 		doc := map[interface{}]interface{}{
 			"xyz": "xyz",
@@ -95,4 +109,201 @@ var _ = Describe("MoveOp.Apply", func() {
 			"xyz": "xyz",
 		}))
 	})
+
+	It("moves array item before earlier item within the same array", func() {
+		doc := map[interface{}]interface{}{
+			"items": []interface{}{"a", "b", "c", "d"},
+		}
+
+		res, err := MoveOp{Path: MustNewPointerFromString("/items/0:before"), From: MustNewPointerFromString("/items/3")}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(map[interface{}]interface{}{
+			"items": []interface{}{"d", "a", "b", "c"},
+		}))
+	})
+
+	It("moves array item after later item within the same array", func() {
+		doc := map[interface{}]interface{}{
+			"items": []interface{}{"a", "b", "c", "d"},
+		}
+
+		res, err := MoveOp{Path: MustNewPointerFromString("/items/2:after"), From: MustNewPointerFromString("/items/0")}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(map[interface{}]interface{}{
+			"items": []interface{}{"b", "c", "a", "d"},
+		}))
+
+		doc = map[interface{}]interface{}{
+			"items": []interface{}{"a", "b", "c", "d"},
+		}
+
+		res, err = MoveOp{Path: MustNewPointerFromString("/items/-"), From: MustNewPointerFromString("/items/1")}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(map[interface{}]interface{}{
+			"items": []interface{}{"a", "c", "d", "b"},
+		}))
+	})
+
+	It("moves array item in place of another item within the same array", func() {
+		doc := map[interface{}]interface{}{
+			"items": []interface{}{"a", "b", "c", "d"},
+		}
+
+		res, err := MoveOp{Path: MustNewPointerFromString("/items/0"), From: MustNewPointerFromString("/items/2")}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(map[interface{}]interface{}{
+			"items": []interface{}{"c", "b", "d"},
+		}))
+	})
+
+	It("returns an error if path refers to the moved item itself", func() {
+		doc := map[interface{}]interface{}{
+			"items": []interface{}{
+				map[interface{}]interface{}{"name": "a"},
+				map[interface{}]interface{}{"name": "b"},
+			},
+		}
+
+		_, err := MoveOp{
+			Path: MustNewPointerFromString("/items/name=a:after"),
+			From: MustNewPointerFromString("/items/name=a"),
+		}.Apply(doc)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal(
+			"Expected to find exactly one matching array item for path '/items/name=a:after' but found 0"))
+
+		Expect(doc).To(Equal(map[interface{}]interface{}{
+			"items": []interface{}{
+				map[interface{}]interface{}{"name": "a"},
+				map[interface{}]interface{}{"name": "b"},
+			},
+		}))
+	})
+
+	It("moves matching array items relative to other matching items within the same array", func() {
+		doc := map[interface{}]interface{}{
+			"instance_groups": []interface{}{
+				map[interface{}]interface{}{"name": "db"},
+				map[interface{}]interface{}{"name": "api"},
+				map[interface{}]interface{}{"name": "worker"},
+			},
+		}
+
+		res, err := MoveOp{
+			Path: MustNewPointerFromString("/instance_groups/name=db:after"),
+			From: MustNewPointerFromString("/instance_groups/name=worker"),
+		}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(map[interface{}]interface{}{
+			"instance_groups": []interface{}{
+				map[interface{}]interface{}{"name": "db"},
+				map[interface{}]interface{}{"name": "worker"},
+				map[interface{}]interface{}{"name": "api"},
+			},
+		}))
+
+		res, err = MoveOp{
+			Path: MustNewPointerFromString("/instance_groups/name=worker:next:before"),
+			From: MustNewPointerFromString("/instance_groups/name=db"),
+		}.Apply(res)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(map[interface{}]interface{}{
+			"instance_groups": []interface{}{
+				map[interface{}]interface{}{"name": "worker"},
+				map[interface{}]interface{}{"name": "db"},
+				map[interface{}]interface{}{"name": "api"},
+			},
+		}))
+	})
+
+	It("moves missing optional matching item next to matching item within the same array", func() {
+		doc := map[interface{}]interface{}{
+			"instance_groups": []interface{}{
+				map[interface{}]interface{}{"name": "db"},
+				map[interface{}]interface{}{"name": "api"},
+				map[interface{}]interface{}{"name": "worker"},
+			},
+		}
+
+		res, err := MoveOp{
+			Path: MustNewPointerFromString("/instance_groups/name=web?:before=name=api"),
+			From: MustNewPointerFromString("/instance_groups/name=worker"),
+		}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(map[interface{}]interface{}{
+			"instance_groups": []interface{}{
+				map[interface{}]interface{}{"name": "db"},
+				map[interface{}]interface{}{"name": "worker"},
+				map[interface{}]interface{}{"name": "api"},
+			},
+		}))
+	})
+
+	It("moves range of array items within the same array", func() {
+		doc := map[interface{}]interface{}{
+			"items": []interface{}{"a", "b", "c", "d", "e"},
+		}
+
+		res, err := MoveOp{Path: MustNewPointerFromString("/items/0..0"), From: MustNewPointerFromString("/items/3..")}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(map[interface{}]interface{}{
+			"items": []interface{}{"d", "e", "a", "b", "c"},
+		}))
+	})
+
+	It("moves array item into another array item", func() {
+		doc := map[interface{}]interface{}{
+			"items": []interface{}{
+				"a",
+				map[interface{}]interface{}{"name": "b"},
+			},
+		}
+
+		res, err := MoveOp{Path: MustNewPointerFromString("/items/1/value?"), From: MustNewPointerFromString("/items/0")}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(map[interface{}]interface{}{
+			"items": []interface{}{
+				map[interface{}]interface{}{"name": "b", "value": "a"},
+			},
+		}))
+	})
+
+	It("returns an error if path is within moved value", func() {
+		doc := map[interface{}]interface{}{
+			"items": []interface{}{
+				map[interface{}]interface{}{"name": "a", "nested": []interface{}{}},
+			},
+		}
+
+		_, err := MoveOp{Path: MustNewPointerFromString("/items/name=a/nested/-"), From: MustNewPointerFromString("/items/0")}.Apply(doc)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected path '/items/name=a/nested/-' to not be within moved value at path '/items/0'"))
+
+		_, err = MoveOp{Path: MustNewPointerFromString("/items/0/name"), From: MustNewPointerFromString("/items/0..1")}.Apply(doc)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected path '/items/0/name' to not be within moved value at path '/items/..1'"))
+	})
+
+	It("leaves document unchanged if path cannot be resolved", func() {
+		doc := map[interface{}]interface{}{
+			"items": []interface{}{"a", "b"},
+		}
+
+		_, err := MoveOp{Path: MustNewPointerFromString("/items/0/name"), From: MustNewPointerFromString("/items/1")}.Apply(doc)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find a map at path '/items/0/name' but found 'string'"))
+
+		Expect(doc).To(Equal(map[interface{}]interface{}{
+			"items": []interface{}{"a", "b"},
+		}))
+	})
 })
@@ -2,18 +2,24 @@ package patch
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
 )
 
 // OpDefinition struct is useful for JSON and YAML unmarshaling
 type OpDefinition struct {
-	Type   string       `json:",omitempty" yaml:",omitempty"`
-	From   *string      `json:",omitempty" yaml:",omitempty"`
-	Path   *string      `json:",omitempty" yaml:",omitempty"`
-	Value  *interface{} `json:",omitempty" yaml:",omitempty"`
-	Absent *bool        `json:",omitempty" yaml:",omitempty"`
-	Error  *string      `json:",omitempty" yaml:",omitempty"`
+	Type      string            `json:",omitempty" yaml:",omitempty"`
+	From      *string           `json:",omitempty" yaml:",omitempty"`
+	Path      *string           `json:",omitempty" yaml:",omitempty"`
+	Value     *interface{}      `json:",omitempty" yaml:",omitempty"`
+	Absent    *bool             `json:",omitempty" yaml:",omitempty"`
+	Conflict  *string           `json:",omitempty" yaml:",omitempty"`
+	MergeKeys map[string]string `json:"merge_keys,omitempty" yaml:"merge_keys,omitempty"`
+	Key       *string           `json:",omitempty" yaml:",omitempty"`
+	By        *string           `json:",omitempty" yaml:",omitempty"`
+	Error     *string           `json:",omitempty" yaml:",omitempty"`
 }
 
 type parser struct{}
@@ -47,12 +53,93 @@ func NewOpsFromDefinitions(opDefs []OpDefinition) (Ops, error) {
 				return nil, fmt.Errorf("Move operation [%d]: %s within\n%s", i, err, opFmt)
 			}
 
+		case "swap":
+			op, err = p.newSwapOp(opDef)
+			if err != nil {
+				return nil, fmt.Errorf("Swap operation [%d]: %s within\n%s", i, err, opFmt)
+			}
+
+		case "copy":
+			op, err = p.newCopyOp(opDef)
+			if err != nil {
+				return nil, fmt.Errorf("Copy operation [%d]: %s within\n%s", i, err, opFmt)
+			}
+
+		case "append":
+			op, err = p.newAppendOp(opDef)
+			if err != nil {
+				return nil, fmt.Errorf("Append operation [%d]: %s within\n%s", i, err, opFmt)
+			}
+
+		case "prepend":
+			op, err = p.newPrependOp(opDef)
+			if err != nil {
+				return nil, fmt.Errorf("Prepend operation [%d]: %s within\n%s", i, err, opFmt)
+			}
+
+		case "concat":
+			op, err = p.newConcatOp(opDef)
+			if err != nil {
+				return nil, fmt.Errorf("Concat operation [%d]: %s within\n%s", i, err, opFmt)
+			}
+
+		case "union":
+			op, err = p.newUnionOp(opDef)
+			if err != nil {
+				return nil, fmt.Errorf("Union operation [%d]: %s within\n%s", i, err, opFmt)
+			}
+
+		case "sort":
+			op, err = p.newSortOp(opDef)
+			if err != nil {
+				return nil, fmt.Errorf("Sort operation [%d]: %s within\n%s", i, err, opFmt)
+			}
+
+		case "dedupe":
+			op, err = p.newDedupeOp(opDef)
+			if err != nil {
+				return nil, fmt.Errorf("Dedupe operation [%d]: %s within\n%s", i, err, opFmt)
+			}
+
+		case "merge":
+			op, err = p.newMergeOp(opDef)
+			if err != nil {
+				return nil, fmt.Errorf("Merge operation [%d]: %s within\n%s", i, err, opFmt)
+			}
+
+		case "strategic_merge":
+			op, err = p.newStrategicMergeOp(opDef)
+			if err != nil {
+				return nil, fmt.Errorf("Strategic merge operation [%d]: %s within\n%s", i, err, opFmt)
+			}
+
+		case "merge_patch":
+			op, err = p.newMergePatchOp(opDef)
+			if err != nil {
+				return nil, fmt.Errorf("Merge patch operation [%d]: %s within\n%s", i, err, opFmt)
+			}
+
+		case "rfc6902_add", "rfc6902_remove", "rfc6902_replace", "rfc6902_move", "rfc6902_copy", "rfc6902_test":
+			op, err = p.newRFC6902Op(opDef)
+			if err != nil {
+				return nil, fmt.Errorf("RFC 6902 operation [%d]: %s within\n%s", i, err, opFmt)
+			}
+
 		case "test":
 			op, err = p.newTestOp(opDef)
 			if err != nil {
 				return nil, fmt.Errorf("Test operation [%d]: %s within\n%s", i, err, opFmt)
 			}
 
+		case "error":
+			op, err = p.newErrOp(opDef)
+			if err != nil {
+				return nil, fmt.Errorf("Error operation [%d]: %s within\n%s", i, err, opFmt)
+			}
+
+			ops = append(ops, op)
+			continue
+
 		default:
 			return nil, fmt.Errorf("Unknown operation [%d] with type '%s' within\n%s", i, opDef.Type, opFmt)
 		}
@@ -131,6 +218,293 @@ func (parser) newMoveOp(opDef OpDefinition) (MoveOp, error) {
 	return MoveOp{From: fromPtr, Path: pathPtr}, nil
 }
 
+func (parser) newSwapOp(opDef OpDefinition) (SwapOp, error) {
+	if opDef.Path == nil {
+		return SwapOp{}, fmt.Errorf("Missing path")
+	}
+
+	if opDef.From == nil {
+		return SwapOp{}, fmt.Errorf("Missing from path")
+	}
+
+	if *opDef.From == *opDef.Path {
+		return SwapOp{}, fmt.Errorf("From and path cannot be the same value")
+	}
+
+	if opDef.Value != nil {
+		return SwapOp{}, fmt.Errorf("Cannot specify value")
+	}
+
+	fromPtr, err := NewPointerFromString(*opDef.From)
+	if err != nil {
+		return SwapOp{}, fmt.Errorf("Invalid from path: %s", err)
+	}
+
+	pathPtr, err := NewPointerFromString(*opDef.Path)
+	if err != nil {
+		return SwapOp{}, fmt.Errorf("Invalid path: %s", err)
+	}
+
+	return SwapOp{From: fromPtr, Path: pathPtr}, nil
+}
+
+func (parser) newCopyOp(opDef OpDefinition) (CopyOp, error) {
+	if opDef.Path == nil {
+		return CopyOp{}, fmt.Errorf("Missing path")
+	}
+
+	if opDef.From == nil {
+		return CopyOp{}, fmt.Errorf("Missing from path")
+	}
+
+	if opDef.Value != nil {
+		return CopyOp{}, fmt.Errorf("Cannot specify value")
+	}
+
+	fromPtr, err := NewPointerFromString(*opDef.From)
+	if err != nil {
+		return CopyOp{}, fmt.Errorf("Invalid from path: %s", err)
+	}
+
+	pathPtr, err := NewPointerFromString(*opDef.Path)
+	if err != nil {
+		return CopyOp{}, fmt.Errorf("Invalid path: %s", err)
+	}
+
+	return CopyOp{From: fromPtr, Path: pathPtr}, nil
+}
+
+func (p parser) newAppendOp(opDef OpDefinition) (AppendOp, error) {
+	ptr, val, err := p.arrayPathAndValue(opDef)
+	if err != nil {
+		return AppendOp{}, err
+	}
+
+	return AppendOp{Path: ptr, Value: val}, nil
+}
+
+func (p parser) newPrependOp(opDef OpDefinition) (PrependOp, error) {
+	ptr, val, err := p.arrayPathAndValue(opDef)
+	if err != nil {
+		return PrependOp{}, err
+	}
+
+	return PrependOp{Path: ptr, Value: val}, nil
+}
+
+func (p parser) newUnionOp(opDef OpDefinition) (UnionOp, error) {
+	ptr, val, err := p.arrayPathAndValue(opDef)
+	if err != nil {
+		return UnionOp{}, err
+	}
+
+	op := UnionOp{Path: ptr, Value: val}
+
+	if opDef.Key != nil {
+		if len(*opDef.Key) == 0 {
+			return UnionOp{}, fmt.Errorf("Expected key to not be empty")
+		}
+		op.Key = *opDef.Key
+	}
+
+	return op, nil
+}
+
+func (p parser) newSortOp(opDef OpDefinition) (SortOp, error) {
+	ptr, key, err := p.arrayPathAndItemKey(opDef)
+	if err != nil {
+		return SortOp{}, err
+	}
+
+	return SortOp{Path: ptr, Key: key.Key, By: key.By}, nil
+}
+
+func (p parser) newDedupeOp(opDef OpDefinition) (DedupeOp, error) {
+	ptr, key, err := p.arrayPathAndItemKey(opDef)
+	if err != nil {
+		return DedupeOp{}, err
+	}
+
+	return DedupeOp{Path: ptr, Key: key.Key, By: key.By}, nil
+}
+
+func (parser) arrayPathAndItemKey(opDef OpDefinition) (Pointer, arrayItemKey, error) {
+	var key arrayItemKey
+
+	if opDef.Path == nil {
+		return Pointer{}, key, fmt.Errorf("Missing path")
+	}
+
+	if opDef.Value != nil {
+		return Pointer{}, key, fmt.Errorf("Cannot specify value")
+	}
+
+	if opDef.Key != nil && opDef.By != nil {
+		return Pointer{}, key, fmt.Errorf("Cannot specify both key and by")
+	}
+
+	if opDef.Key != nil {
+		if len(*opDef.Key) == 0 {
+			return Pointer{}, key, fmt.Errorf("Expected key to not be empty")
+		}
+		key.Key = *opDef.Key
+	}
+
+	if opDef.By != nil {
+		byPtr, err := NewPointerFromString(*opDef.By)
+		if err != nil {
+			return Pointer{}, key, fmt.Errorf("Invalid by path: %s", err)
+		}
+		key.By = byPtr
+	}
+
+	ptr, err := NewPointerFromString(*opDef.Path)
+	if err != nil {
+		return Pointer{}, key, fmt.Errorf("Invalid path: %s", err)
+	}
+
+	return ptr, key, nil
+}
+
+func (parser) arrayPathAndValue(opDef OpDefinition) (Pointer, interface{}, error) {
+	if opDef.Path == nil {
+		return Pointer{}, nil, fmt.Errorf("Missing path")
+	}
+
+	if opDef.Value == nil {
+		return Pointer{}, nil, fmt.Errorf("Missing value")
+	}
+
+	if _, ok := (*opDef.Value).([]interface{}); !ok {
+		return Pointer{}, nil, fmt.Errorf("Expected value to be an array but found '%T'", *opDef.Value)
+	}
+
+	ptr, err := NewPointerFromString(*opDef.Path)
+	if err != nil {
+		return Pointer{}, nil, fmt.Errorf("Invalid path: %s", err)
+	}
+
+	return ptr, *opDef.Value, nil
+}
+
+func (parser) newConcatOp(opDef OpDefinition) (ConcatOp, error) {
+	if opDef.Path == nil {
+		return ConcatOp{}, fmt.Errorf("Missing path")
+	}
+
+	if opDef.From == nil {
+		return ConcatOp{}, fmt.Errorf("Missing from path")
+	}
+
+	if opDef.Value != nil {
+		return ConcatOp{}, fmt.Errorf("Cannot specify value")
+	}
+
+	fromPtr, err := NewPointerFromString(*opDef.From)
+	if err != nil {
+		return ConcatOp{}, fmt.Errorf("Invalid from path: %s", err)
+	}
+
+	pathPtr, err := NewPointerFromString(*opDef.Path)
+	if err != nil {
+		return ConcatOp{}, fmt.Errorf("Invalid path: %s", err)
+	}
+
+	return ConcatOp{From: fromPtr, Path: pathPtr}, nil
+}
+
+func (parser) newMergeOp(opDef OpDefinition) (MergeOp, error) {
+	if opDef.Path == nil {
+		return MergeOp{}, fmt.Errorf("Missing path")
+	}
+
+	if opDef.Value == nil {
+		return MergeOp{}, fmt.Errorf("Missing value")
+	}
+
+	ptr, err := NewPointerFromString(*opDef.Path)
+	if err != nil {
+		return MergeOp{}, fmt.Errorf("Invalid path: %s", err)
+	}
+
+	op := MergeOp{Path: ptr, Value: *opDef.Value}
+
+	if opDef.Conflict != nil {
+		op.Conflict = MergeConflict(*opDef.Conflict)
+
+		err = op.checkConflict()
+		if err != nil {
+			return MergeOp{}, fmt.Errorf("Invalid conflict: %s", err)
+		}
+	}
+
+	return op, nil
+}
+
+func (p parser) newStrategicMergeOp(opDef OpDefinition) (StrategicMergeOp, error) {
+	mergeOp, err := p.newMergeOp(opDef)
+	if err != nil {
+		return StrategicMergeOp{}, err
+	}
+
+	op := StrategicMergeOp{Path: mergeOp.Path, Value: mergeOp.Value, Conflict: mergeOp.Conflict}
+
+	var paths []string
+
+	for path := range opDef.MergeKeys {
+		paths = append(paths, path)
+	}
+
+	// Merge key paths must not overlap so that their order does not matter
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		ptr, err := NewPointerFromString(path)
+		if err != nil {
+			return StrategicMergeOp{}, fmt.Errorf("Invalid merge key path: %s", err)
+		}
+
+		op.MergeKeys = append(op.MergeKeys, MergeKey{Path: ptr, Key: opDef.MergeKeys[path]})
+	}
+
+	err = op.checkMergeKeys()
+	if err != nil {
+		return StrategicMergeOp{}, err
+	}
+
+	return op, nil
+}
+
+// newRFC6902Op parses operation that follows RFC 6902 semantics
+// (ex: type 'rfc6902_add' with plain RFC 6901 path)
+func (parser) newRFC6902Op(opDef OpDefinition) (RFC6902Op, error) {
+	def := RFC6902Definition{
+		Op:    strings.TrimPrefix(opDef.Type, "rfc6902_"),
+		Path:  opDef.Path,
+		From:  opDef.From,
+		Value: opDef.Value,
+	}
+
+	return newRFC6902Op(def)
+}
+
+func (parser) newMergePatchOp(opDef OpDefinition) (MergePatchOp, error) {
+	if opDef.Path == nil {
+		return MergePatchOp{}, fmt.Errorf("Missing path")
+	}
+
+	if opDef.Value == nil {
+		return MergePatchOp{}, fmt.Errorf("Missing value")
+	}
+
+	ptr, err := NewPointerFromString(*opDef.Path)
+	if err != nil {
+		return MergePatchOp{}, fmt.Errorf("Invalid path: %s", err)
+	}
+
+	return MergePatchOp{Path: ptr, Value: *opDef.Value}, nil
+}
+
 func (parser) newTestOp(opDef OpDefinition) (TestOp, error) {
 	if opDef.Path == nil {
 		return TestOp{}, fmt.Errorf("Missing path")
@@ -158,6 +532,22 @@ func (parser) newTestOp(opDef OpDefinition) (TestOp, error) {
 	return op, nil
 }
 
+func (parser) newErrOp(opDef OpDefinition) (ErrOp, error) {
+	if opDef.Error == nil {
+		return ErrOp{}, fmt.Errorf("Missing error")
+	}
+
+	if opDef.Path != nil || opDef.From != nil {
+		return ErrOp{}, fmt.Errorf("Cannot specify path")
+	}
+
+	if opDef.Value != nil {
+		return ErrOp{}, fmt.Errorf("Cannot specify value")
+	}
+
+	return ErrOp{Err: errors.New(*opDef.Error)}, nil
+}
+
 func (parser) fmtOpDef(opDef OpDefinition) string {
 	var (
 		redactedVal interface{} = "<redacted>"
@@ -181,46 +571,308 @@ func NewOpDefinitionsFromOps(ops Ops) ([]OpDefinition, error) {
 	opDefs := []OpDefinition{}
 
 	for i, op := range ops {
-		switch typedOp := op.(type) {
-		case ReplaceOp:
-			path := typedOp.Path.String()
-			val := typedOp.Value
+		newOpDefs, err := newOpDefinitions(op)
+		if err != nil {
+			return nil, fmt.Errorf("Operation [%d]: %s", i, err)
+		}
 
-			opDefs = append(opDefs, OpDefinition{
-				Type:  "replace",
-				Path:  &path,
-				Value: &val,
-			})
+		opDefs = append(opDefs, newOpDefs...)
+	}
 
-		case RemoveOp:
-			path := typedOp.Path.String()
+	return opDefs, nil
+}
 
-			opDefs = append(opDefs, OpDefinition{
-				Type: "remove",
-				Path: &path,
-			})
+func newOpDefinitions(op Op) ([]OpDefinition, error) {
+	switch typedOp := op.(type) {
+	case Ops:
+		var opDefs []OpDefinition
 
-		case TestOp:
-			path := typedOp.Path.String()
-			val := typedOp.Value
+		for _, op := range typedOp {
+			newOpDefs, err := newOpDefinitions(op)
+			if err != nil {
+				return nil, err
+			}
+
+			opDefs = append(opDefs, newOpDefs...)
+		}
+
+		return opDefs, nil
+
+	case ReplaceOp:
+		path := typedOp.Path.String()
+		val := typedOp.Value
+
+		return []OpDefinition{{Type: "replace", Path: &path, Value: &val}}, nil
+
+	case RemoveOp:
+		path := typedOp.Path.String()
+
+		return []OpDefinition{{Type: "remove", Path: &path}}, nil
+
+	case MoveOp:
+		path := typedOp.Path.String()
+		from := typedOp.From.String()
+
+		return []OpDefinition{{Type: "move", From: &from, Path: &path}}, nil
+
+	case SwapOp:
+		path := typedOp.Path.String()
+		from := typedOp.From.String()
+
+		return []OpDefinition{{Type: "swap", From: &from, Path: &path}}, nil
+
+	case CopyOp:
+		path := typedOp.Path.String()
+		from := typedOp.From.String()
+
+		return []OpDefinition{{Type: "copy", From: &from, Path: &path}}, nil
+
+	case AppendOp:
+		path := typedOp.Path.String()
+		val := typedOp.Value
+
+		return []OpDefinition{{Type: "append", Path: &path, Value: &val}}, nil
+
+	case PrependOp:
+		path := typedOp.Path.String()
+		val := typedOp.Value
+
+		return []OpDefinition{{Type: "prepend", Path: &path, Value: &val}}, nil
+
+	case ConcatOp:
+		path := typedOp.Path.String()
+		from := typedOp.From.String()
+
+		return []OpDefinition{{Type: "concat", From: &from, Path: &path}}, nil
+
+	case UnionOp:
+		path := typedOp.Path.String()
+		val := typedOp.Value
+
+		opDef := OpDefinition{Type: "union", Path: &path, Value: &val}
+
+		if len(typedOp.Key) > 0 {
+			key := typedOp.Key
+			opDef.Key = &key
+		}
+
+		return []OpDefinition{opDef}, nil
+
+	case SortOp:
+		path := typedOp.Path.String()
+
+		opDef := OpDefinition{Type: "sort", Path: &path}
 
-			opDef := OpDefinition{
-				Type: "test",
-				Path: &path,
+		if len(typedOp.Key) > 0 {
+			key := typedOp.Key
+			opDef.Key = &key
+		}
+
+		if typedOp.By.IsSet() {
+			by := typedOp.By.String()
+			opDef.By = &by
+		}
+
+		return []OpDefinition{opDef}, nil
+
+	case DedupeOp:
+		path := typedOp.Path.String()
+
+		opDef := OpDefinition{Type: "dedupe", Path: &path}
+
+		if len(typedOp.Key) > 0 {
+			key := typedOp.Key
+			opDef.Key = &key
+		}
+
+		if typedOp.By.IsSet() {
+			by := typedOp.By.String()
+			opDef.By = &by
+		}
+
+		return []OpDefinition{opDef}, nil
+
+	case MergeOp:
+		path := typedOp.Path.String()
+		val := typedOp.Value
+
+		opDef := OpDefinition{Type: "merge", Path: &path, Value: &val}
+
+		if len(typedOp.Conflict) > 0 {
+			conflict := string(typedOp.Conflict)
+			opDef.Conflict = &conflict
+		}
+
+		return []OpDefinition{opDef}, nil
+
+	case StrategicMergeOp:
+		path := typedOp.Path.String()
+		val := typedOp.Value
+
+		opDef := OpDefinition{Type: "strategic_merge", Path: &path, Value: &val}
+
+		if len(typedOp.Conflict) > 0 {
+			conflict := string(typedOp.Conflict)
+			opDef.Conflict = &conflict
+		}
+
+		if len(typedOp.MergeKeys) > 0 {
+			opDef.MergeKeys = map[string]string{}
+
+			for _, mergeKey := range typedOp.MergeKeys {
+				opDef.MergeKeys[mergeKey.Path.String()] = mergeKey.Key
 			}
+		}
+
+		return []OpDefinition{opDef}, nil
+
+	case MergePatchOp:
+		path := typedOp.Path.String()
+		val := typedOp.Value
+
+		return []OpDefinition{{Type: "merge_patch", Path: &path, Value: &val}}, nil
+
+	case RFC6902Op:
+		path := typedOp.Path
+
+		opDef := OpDefinition{Type: "rfc6902_" + typedOp.Op, Path: &path}
+
+		switch typedOp.Op {
+		case "move", "copy":
+			from := typedOp.From
+			opDef.From = &from
+		case "add", "replace", "test":
+			val := typedOp.Value
+			opDef.Value = &val
+		}
+
+		return []OpDefinition{opDef}, nil
+
+	case TestOp:
+		path := typedOp.Path.String()
+		val := typedOp.Value
 
-			if typedOp.Absent {
-				opDef.Absent = &typedOp.Absent
-			} else {
-				opDef.Value = &val
+		opDef := OpDefinition{
+			Type: "test",
+			Path: &path,
+		}
+
+		if typedOp.Absent {
+			opDef.Absent = &typedOp.Absent
+		} else {
+			opDef.Value = &val
+		}
+
+		return []OpDefinition{opDef}, nil
+
+	case DescriptiveOp:
+		opDefs, err := newOpDefinitions(typedOp.Op)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range opDefs {
+			if opDefs[i].Error != nil {
+				return nil, fmt.Errorf("Expected described operation to not have its own error message but found '%s'", *opDefs[i].Error)
 			}
 
-			opDefs = append(opDefs, opDef)
+			errMsg := typedOp.ErrorMsg
+			opDefs[i].Error = &errMsg
+		}
 
-		default:
-			return nil, fmt.Errorf("Unknown operation [%d] with type '%t'", i, op)
+		return opDefs, nil
+
+	case ErrOp:
+		if typedOp.Err == nil {
+			return nil, fmt.Errorf("Expected error operation to have an error")
 		}
+
+		errMsg := typedOp.Err.Error()
+
+		return []OpDefinition{{Type: "error", Error: &errMsg}}, nil
+
+	default:
+		return nil, fmt.Errorf("Unknown operation with type '%T'", op)
 	}
+}
 
-	return opDefs, nil
+// MarshalYAML serializes operations as a list of operation definitions
+func (ops Ops) MarshalYAML() (interface{}, error) {
+	return NewOpDefinitionsFromOps(ops)
+}
+
+// UnmarshalYAML parses a list of operation definitions
+func (ops *Ops) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var opDefs []OpDefinition
+
+	err := unmarshal(&opDefs)
+	if err != nil {
+		return err
+	}
+
+	newOps, err := NewOpsFromDefinitions(opDefs)
+	if err != nil {
+		return err
+	}
+
+	*ops = newOps
+
+	return nil
+}
+
+// MarshalJSON serializes operations as a list of operation definitions
+// converting maps with interface{} keys (produced by YAML library) into JSON objects
+func (ops Ops) MarshalJSON() ([]byte, error) {
+	opDefs, err := NewOpDefinitionsFromOps(ops)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, opDef := range opDefs {
+		if opDef.Value != nil {
+			val := jsonValue(*opDef.Value)
+			opDefs[i].Value = &val
+		}
+	}
+
+	return json.Marshal(opDefs)
+}
+
+// UnmarshalJSON parses a list of operation definitions
+// keeping explicit null values and decoding values the same way YAML library does
+func (ops *Ops) UnmarshalJSON(data []byte) error {
+	var rawOpDefs []struct {
+		OpDefinition
+		Value json.RawMessage
+	}
+
+	err := json.Unmarshal(data, &rawOpDefs)
+	if err != nil {
+		return err
+	}
+
+	var opDefs []OpDefinition
+
+	for _, rawOpDef := range rawOpDefs {
+		opDef := rawOpDef.OpDefinition
+
+		if rawOpDef.Value != nil {
+			val, err := decodeJSONValue(rawOpDef.Value)
+			if err != nil {
+				return err
+			}
+			opDef.Value = &val
+		}
+
+		opDefs = append(opDefs, opDef)
+	}
+
+	newOps, err := NewOpsFromDefinitions(opDefs)
+	if err != nil {
+		return err
+	}
+
+	*ops = newOps
+
+	return nil
 }
@@ -2,6 +2,7 @@ package patch_test
 
 import (
 	"encoding/json"
+	"errors"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -20,15 +21,36 @@ var _ = Describe("NewOpsFromDefinitions", func() {
 		val         interface{} = 123
 		complexVal  interface{} = map[interface{}]interface{}{123: 123}
 		trueBool                = true
+		conflict                = "keep"
+		key                     = "name"
+		by                      = "/name"
+		arrayVal    interface{} = []interface{}{123}
 	)
 
-	It("supports 'replace', 'remove', 'move', 'test' operations", func() {
+	It("supports 'replace', 'remove', 'move', 'swap', 'copy', 'append', 'prepend', 'concat', 'union', 'sort', 'dedupe', 'merge', 'merge_patch', 'test', 'error' operations", func() {
 		opDefs := []OpDefinition{
 			{Type: "replace", Path: &path, Value: &val},
 			{Type: "remove", Path: &path},
 			{Type: "move", From: &from, Path: &path},
+			{Type: "swap", From: &from, Path: &path},
+			{Type: "copy", From: &from, Path: &path},
+			{Type: "append", Path: &path, Value: &arrayVal},
+			{Type: "prepend", Path: &path, Value: &arrayVal},
+			{Type: "concat", From: &from, Path: &path},
+			{Type: "union", Path: &path, Value: &arrayVal},
+			{Type: "union", Path: &path, Value: &arrayVal, Key: &key},
+			{Type: "sort", Path: &path},
+			{Type: "sort", Path: &path, By: &by},
+			{Type: "dedupe", Path: &path},
+			{Type: "dedupe", Path: &path, Key: &key},
+			{Type: "merge", Path: &path, Value: &val},
+			{Type: "merge", Path: &path, Value: &val, Conflict: &conflict},
+			{Type: "merge_patch", Path: &path, Value: &val},
+			{Type: "rfc6902_add", Path: &path, Value: &val},
+			{Type: "rfc6902_copy", Path: &path, From: &from},
 			{Type: "test", Path: &path, Value: &val},
 			{Type: "test", Path: &path, Absent: &trueBool},
+			{Type: "error", Error: &errorMsg},
 		}
 
 		ops, err := NewOpsFromDefinitions(opDefs)
@@ -38,8 +60,25 @@ var _ = Describe("NewOpsFromDefinitions", func() {
 			ReplaceOp{Path: MustNewPointerFromString("/abc"), Value: 123},
 			RemoveOp{Path: MustNewPointerFromString("/abc")},
 			MoveOp{Path: MustNewPointerFromString("/abc"), From: MustNewPointerFromString("/old")},
+			SwapOp{Path: MustNewPointerFromString("/abc"), From: MustNewPointerFromString("/old")},
+			CopyOp{Path: MustNewPointerFromString("/abc"), From: MustNewPointerFromString("/old")},
+			AppendOp{Path: MustNewPointerFromString("/abc"), Value: []interface{}{123}},
+			PrependOp{Path: MustNewPointerFromString("/abc"), Value: []interface{}{123}},
+			ConcatOp{Path: MustNewPointerFromString("/abc"), From: MustNewPointerFromString("/old")},
+			UnionOp{Path: MustNewPointerFromString("/abc"), Value: []interface{}{123}},
+			UnionOp{Path: MustNewPointerFromString("/abc"), Value: []interface{}{123}, Key: "name"},
+			SortOp{Path: MustNewPointerFromString("/abc")},
+			SortOp{Path: MustNewPointerFromString("/abc"), By: MustNewPointerFromString("/name")},
+			DedupeOp{Path: MustNewPointerFromString("/abc")},
+			DedupeOp{Path: MustNewPointerFromString("/abc"), Key: "name"},
+			MergeOp{Path: MustNewPointerFromString("/abc"), Value: 123},
+			MergeOp{Path: MustNewPointerFromString("/abc"), Value: 123, Conflict: MergeConflictKeep},
+			MergePatchOp{Path: MustNewPointerFromString("/abc"), Value: 123},
+			RFC6902Op{Op: "add", Path: "/abc", Value: 123},
+			RFC6902Op{Op: "copy", Path: "/abc", From: "/old"},
 			TestOp{Path: MustNewPointerFromString("/abc"), Value: 123},
 			TestOp{Path: MustNewPointerFromString("/abc"), Absent: true},
+			ErrOp{Err: errors.New("error")},
 		})))
 	})
 
@@ -242,6 +281,596 @@ var _ = Describe("NewOpsFromDefinitions", func() {
 		})
 	})
 
+	Describe("swap", func() {
+		It("allows error description", func() {
+			opDefs := []OpDefinition{{Type: "swap", From: &from, Path: &path, Error: &errorMsg}}
+
+			ops, err := NewOpsFromDefinitions(opDefs)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ops).To(Equal(Ops([]Op{
+				DescriptiveOp{
+					Op:       SwapOp{Path: MustNewPointerFromString("/abc"), From: MustNewPointerFromString("/old")},
+					ErrorMsg: errorMsg,
+				},
+			})))
+		})
+
+		It("requires path", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "swap", From: &from}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Swap operation [0]: Missing path within
+{
+  "Type": "swap",
+  "From": "/old"
+}`))
+		})
+
+		It("requires from path", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "swap", Path: &path}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Swap operation [0]: Missing from path within
+{
+  "Type": "swap",
+  "Path": "/abc"
+}`))
+		})
+
+		It("does not allow from and path to be same value", func() {
+			samePath := "/abc"
+
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "swap", From: &samePath, Path: &path}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Swap operation [0]: From and path cannot be the same value within
+{
+  "Type": "swap",
+  "From": "/abc",
+  "Path": "/abc"
+}`))
+		})
+
+		It("does not allow value", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "swap", Path: &path, From: &from, Value: &val}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Swap operation [0]: Cannot specify value within
+{
+  "Type": "swap",
+  "From": "/old",
+  "Path": "/abc",
+  "Value": "<redacted>"
+}`))
+		})
+
+		It("requires valid path", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "swap", From: &from, Path: &invalidPath}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Swap operation [0]: Invalid path: Expected to start with '/' within
+{
+  "Type": "swap",
+  "From": "/old",
+  "Path": "abc"
+}`))
+		})
+
+		It("requires valid from path", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "swap", From: &invalidFrom, Path: &path}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Swap operation [0]: Invalid from path: Expected to start with '/' within
+{
+  "Type": "swap",
+  "From": "old",
+  "Path": "/abc"
+}`))
+		})
+	})
+
+	Describe("copy", func() {
+		It("allows error description", func() {
+			opDefs := []OpDefinition{{Type: "copy", From: &from, Path: &path, Error: &errorMsg}}
+
+			ops, err := NewOpsFromDefinitions(opDefs)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ops).To(Equal(Ops([]Op{
+				DescriptiveOp{
+					Op:       CopyOp{Path: MustNewPointerFromString("/abc"), From: MustNewPointerFromString("/old")},
+					ErrorMsg: errorMsg,
+				},
+			})))
+		})
+
+		It("requires path", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "copy", From: &from}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Copy operation [0]: Missing path within
+{
+  "Type": "copy",
+  "From": "/old"
+}`))
+		})
+
+		It("requires from path", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "copy", Path: &path}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Copy operation [0]: Missing from path within
+{
+  "Type": "copy",
+  "Path": "/abc"
+}`))
+		})
+
+		It("does not allow value", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "copy", Path: &path, From: &from, Value: &val}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Copy operation [0]: Cannot specify value within
+{
+  "Type": "copy",
+  "From": "/old",
+  "Path": "/abc",
+  "Value": "<redacted>"
+}`))
+		})
+
+		It("requires valid path", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "copy", From: &from, Path: &invalidPath}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Copy operation [0]: Invalid path: Expected to start with '/' within
+{
+  "Type": "copy",
+  "From": "/old",
+  "Path": "abc"
+}`))
+		})
+
+		It("requires valid from path", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "copy", From: &invalidFrom, Path: &path}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Copy operation [0]: Invalid from path: Expected to start with '/' within
+{
+  "Type": "copy",
+  "From": "old",
+  "Path": "/abc"
+}`))
+		})
+	})
+
+	Describe("append", func() {
+		It("allows error description", func() {
+			opDefs := []OpDefinition{{Type: "append", Path: &path, Value: &arrayVal, Error: &errorMsg}}
+
+			ops, err := NewOpsFromDefinitions(opDefs)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ops).To(Equal(Ops([]Op{
+				DescriptiveOp{
+					Op:       AppendOp{Path: MustNewPointerFromString("/abc"), Value: []interface{}{123}},
+					ErrorMsg: errorMsg,
+				},
+			})))
+		})
+
+		It("requires path", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "append"}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Append operation [0]: Missing path within
+{
+  "Type": "append"
+}`))
+		})
+
+		It("requires value", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "append", Path: &path}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Append operation [0]: Missing value within
+{
+  "Type": "append",
+  "Path": "/abc"
+}`))
+		})
+
+		It("requires value to be an array", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "append", Path: &path, Value: &val}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Append operation [0]: Expected value to be an array but found 'int' within
+{
+  "Type": "append",
+  "Path": "/abc",
+  "Value": "<redacted>"
+}`))
+		})
+
+		It("requires valid path", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "append", Path: &invalidPath, Value: &arrayVal}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Append operation [0]: Invalid path: Expected to start with '/' within
+{
+  "Type": "append",
+  "Path": "abc",
+  "Value": "<redacted>"
+}`))
+		})
+	})
+
+	Describe("prepend", func() {
+		It("requires value to be an array", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "prepend", Path: &path, Value: &val}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Prepend operation [0]: Expected value to be an array but found 'int' within
+{
+  "Type": "prepend",
+  "Path": "/abc",
+  "Value": "<redacted>"
+}`))
+		})
+	})
+
+	Describe("concat", func() {
+		It("requires from path", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "concat", Path: &path}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Concat operation [0]: Missing from path within
+{
+  "Type": "concat",
+  "Path": "/abc"
+}`))
+		})
+
+		It("does not allow value", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "concat", Path: &path, From: &from, Value: &arrayVal}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Concat operation [0]: Cannot specify value within
+{
+  "Type": "concat",
+  "From": "/old",
+  "Path": "/abc",
+  "Value": "<redacted>"
+}`))
+		})
+
+		It("requires valid from path", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "concat", From: &invalidFrom, Path: &path}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Concat operation [0]: Invalid from path: Expected to start with '/' within
+{
+  "Type": "concat",
+  "From": "old",
+  "Path": "/abc"
+}`))
+		})
+	})
+
+	Describe("union", func() {
+		It("requires value to be an array", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "union", Path: &path, Value: &val}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Union operation [0]: Expected value to be an array but found 'int' within
+{
+  "Type": "union",
+  "Path": "/abc",
+  "Value": "<redacted>"
+}`))
+		})
+
+		It("does not allow empty key", func() {
+			emptyKey := ""
+
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "union", Path: &path, Value: &arrayVal, Key: &emptyKey}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Union operation [0]: Expected key to not be empty within
+{
+  "Type": "union",
+  "Path": "/abc",
+  "Value": "<redacted>",
+  "Key": ""
+}`))
+		})
+	})
+
+	Describe("sort", func() {
+		It("allows error description", func() {
+			opDefs := []OpDefinition{{Type: "sort", Path: &path, Key: &key, Error: &errorMsg}}
+
+			ops, err := NewOpsFromDefinitions(opDefs)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ops).To(Equal(Ops([]Op{
+				DescriptiveOp{
+					Op:       SortOp{Path: MustNewPointerFromString("/abc"), Key: "name"},
+					ErrorMsg: errorMsg,
+				},
+			})))
+		})
+
+		It("requires path", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "sort"}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Sort operation [0]: Missing path within
+{
+  "Type": "sort"
+}`))
+		})
+
+		It("does not allow value", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "sort", Path: &path, Value: &arrayVal}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Sort operation [0]: Cannot specify value within
+{
+  "Type": "sort",
+  "Path": "/abc",
+  "Value": "<redacted>"
+}`))
+		})
+
+		It("does not allow both key and by", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "sort", Path: &path, Key: &key, By: &by}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Sort operation [0]: Cannot specify both key and by within
+{
+  "Type": "sort",
+  "Path": "/abc",
+  "Key": "name",
+  "By": "/name"
+}`))
+		})
+
+		It("requires valid by path", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "sort", Path: &path, By: &invalidPath}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Sort operation [0]: Invalid by path: Expected to start with '/' within
+{
+  "Type": "sort",
+  "Path": "/abc",
+  "By": "abc"
+}`))
+		})
+
+		It("requires valid path", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "sort", Path: &invalidPath}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Sort operation [0]: Invalid path: Expected to start with '/' within
+{
+  "Type": "sort",
+  "Path": "abc"
+}`))
+		})
+	})
+
+	Describe("dedupe", func() {
+		It("does not allow empty key", func() {
+			emptyKey := ""
+
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "dedupe", Path: &path, Key: &emptyKey}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Dedupe operation [0]: Expected key to not be empty within
+{
+  "Type": "dedupe",
+  "Path": "/abc",
+  "Key": ""
+}`))
+		})
+	})
+
+	Describe("merge", func() {
+		It("allows error description", func() {
+			opDefs := []OpDefinition{{Type: "merge", Path: &path, Value: &val, Error: &errorMsg}}
+
+			ops, err := NewOpsFromDefinitions(opDefs)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ops).To(Equal(Ops([]Op{
+				DescriptiveOp{
+					Op:       MergeOp{Path: MustNewPointerFromString("/abc"), Value: 123},
+					ErrorMsg: errorMsg,
+				},
+			})))
+		})
+
+		It("requires path", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "merge"}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Merge operation [0]: Missing path within
+{
+  "Type": "merge"
+}`))
+		})
+
+		It("requires value", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "merge", Path: &path}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Merge operation [0]: Missing value within
+{
+  "Type": "merge",
+  "Path": "/abc"
+}`))
+		})
+
+		It("requires valid path", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "merge", Path: &invalidPath, Value: &val}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Merge operation [0]: Invalid path: Expected to start with '/' within
+{
+  "Type": "merge",
+  "Path": "abc",
+  "Value": "<redacted>"
+}`))
+		})
+
+		It("requires known conflict policy", func() {
+			invalidConflict := "skip"
+
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "merge", Path: &path, Value: &val, Conflict: &invalidConflict}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Merge operation [0]: Invalid conflict: Expected merge conflict policy to be one of 'overwrite', 'keep' or 'error' but found 'skip' within
+{
+  "Type": "merge",
+  "Path": "/abc",
+  "Value": "<redacted>",
+  "Conflict": "skip"
+}`))
+		})
+	})
+
+	Describe("strategic_merge", func() {
+		It("parses merge keys in sorted order of their paths", func() {
+			opDefs := []OpDefinition{{
+				Type:      "strategic_merge",
+				Path:      &path,
+				Value:     &val,
+				Conflict:  &conflict,
+				MergeKeys: map[string]string{"/instance_groups/*/jobs": "name", "/instance_groups": "name"},
+			}}
+
+			ops, err := NewOpsFromDefinitions(opDefs)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ops).To(Equal(Ops([]Op{
+				StrategicMergeOp{
+					Path:  MustNewPointerFromString("/abc"),
+					Value: 123,
+					MergeKeys: []MergeKey{
+						{Path: MustNewPointerFromString("/instance_groups"), Key: "name"},
+						{Path: MustNewPointerFromString("/instance_groups/*/jobs"), Key: "name"},
+					},
+					Conflict: MergeConflictKeep,
+				},
+			})))
+
+			newOpDefs, err := NewOpDefinitionsFromOps(ops)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(newOpDefs).To(Equal(opDefs))
+		})
+
+		It("uses the same merge keys field name in JSON and YAML", func() {
+			var opDefs []OpDefinition
+
+			err := json.Unmarshal([]byte(`[{"Type": "strategic_merge", "Path": "/abc", "Value": 123, "merge_keys": {"/jobs": "name"}}]`), &opDefs)
+			Expect(err).ToNot(HaveOccurred())
+
+			ops, err := NewOpsFromDefinitions(opDefs)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ops).To(Equal(Ops([]Op{
+				StrategicMergeOp{
+					Path:      MustNewPointerFromString("/abc"),
+					Value:     float64(123),
+					MergeKeys: []MergeKey{{Path: MustNewPointerFromString("/jobs"), Key: "name"}},
+				},
+			})))
+
+			bs, err := json.Marshal(opDefs)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(bs)).To(MatchJSON(`[{"Type": "strategic_merge", "Path": "/abc", "Value": 123, "merge_keys": {"/jobs": "name"}}]`))
+		})
+
+		It("requires merge key paths to not match the same array", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{
+				Type:      "strategic_merge",
+				Path:      &path,
+				Value:     &val,
+				MergeKeys: map[string]string{"/instance_groups/*/jobs": "name", "/instance_groups/api/jobs": "id"},
+			}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Strategic merge operation [0]: Expected merge key paths '/instance_groups/*/jobs' and '/instance_groups/api/jobs' to not match the same array within"))
+		})
+
+		It("requires path", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "strategic_merge"}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Strategic merge operation [0]: Missing path within
+{
+  "Type": "strategic_merge"
+}`))
+		})
+
+		It("requires value", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "strategic_merge", Path: &path}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Strategic merge operation [0]: Missing value within
+{
+  "Type": "strategic_merge",
+  "Path": "/abc"
+}`))
+		})
+
+		It("requires valid merge key paths", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "strategic_merge", Path: &path, Value: &val, MergeKeys: map[string]string{"jobs": "name"}}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Strategic merge operation [0]: Invalid merge key path: Expected to start with '/' within
+{
+  "Type": "strategic_merge",
+  "Path": "/abc",
+  "Value": "<redacted>",
+  "merge_keys": {
+    "jobs": "name"
+  }
+}`))
+		})
+	})
+
+	Describe("rfc6902", func() {
+		It("requires value for operations that add values", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "rfc6902_add", Path: &path}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`RFC 6902 operation [0]: Missing value within
+{
+  "Type": "rfc6902_add",
+  "Path": "/abc"
+}`))
+		})
+
+		It("requires valid from path", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "rfc6902_move", Path: &path, From: &invalidPath}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("RFC 6902 operation [0]: Invalid from path: "))
+		})
+	})
+
+	Describe("merge_patch", func() {
+		It("allows error description", func() {
+			opDefs := []OpDefinition{{Type: "merge_patch", Path: &path, Value: &val, Error: &errorMsg}}
+
+			ops, err := NewOpsFromDefinitions(opDefs)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ops).To(Equal(Ops([]Op{
+				DescriptiveOp{
+					Op:       MergePatchOp{Path: MustNewPointerFromString("/abc"), Value: 123},
+					ErrorMsg: errorMsg,
+				},
+			})))
+		})
+
+		It("requires path", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "merge_patch"}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Merge patch operation [0]: Missing path within
+{
+  "Type": "merge_patch"
+}`))
+		})
+
+		It("requires value", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "merge_patch", Path: &path}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Merge patch operation [0]: Missing value within
+{
+  "Type": "merge_patch",
+  "Path": "/abc"
+}`))
+		})
+
+		It("requires valid path", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "merge_patch", Path: &invalidPath, Value: &val}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Merge patch operation [0]: Invalid path: Expected to start with '/' within
+{
+  "Type": "merge_patch",
+  "Path": "abc",
+  "Value": "<redacted>"
+}`))
+		})
+	})
+
 	Describe("test", func() {
 		It("allows error description", func() {
 			opDefs := []OpDefinition{{Type: "test", Path: &path, Value: &val, Error: &errorMsg}}
@@ -284,18 +913,72 @@ var _ = Describe("NewOpsFromDefinitions", func() {
   "Type": "test",
   "Path": "abc",
   "Value": "<redacted>"
+}`))
+		})
+	})
+
+	Describe("error", func() {
+		It("requires error", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "error"}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Error operation [0]: Missing error within
+{
+  "Type": "error"
+}`))
+		})
+
+		It("does not allow path", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "error", Path: &path, Error: &errorMsg}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Error operation [0]: Cannot specify path within
+{
+  "Type": "error",
+  "Path": "/abc",
+  "Error": "error"
+}`))
+		})
+
+		It("does not allow value", func() {
+			_, err := NewOpsFromDefinitions([]OpDefinition{{Type: "error", Value: &val, Error: &errorMsg}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`Error operation [0]: Cannot specify value within
+{
+  "Type": "error",
+  "Value": "<redacted>",
+  "Error": "error"
 }`))
 		})
 	})
 })
 
 var _ = Describe("NewOpDefinitionsFromOps", func() {
-	It("supports 'replace', 'remove', 'test' operations serialized", func() {
+	It("supports all operations serialized", func() {
 		ops := Ops([]Op{
 			ReplaceOp{Path: MustNewPointerFromString("/abc"), Value: 123},
 			RemoveOp{Path: MustNewPointerFromString("/abc")},
+			MoveOp{Path: MustNewPointerFromString("/abc"), From: MustNewPointerFromString("/old")},
+			SwapOp{Path: MustNewPointerFromString("/abc"), From: MustNewPointerFromString("/old")},
+			CopyOp{Path: MustNewPointerFromString("/abc"), From: MustNewPointerFromString("/old")},
+			AppendOp{Path: MustNewPointerFromString("/abc"), Value: []interface{}{123}},
+			PrependOp{Path: MustNewPointerFromString("/abc"), Value: []interface{}{123}},
+			ConcatOp{Path: MustNewPointerFromString("/abc"), From: MustNewPointerFromString("/old")},
+			UnionOp{Path: MustNewPointerFromString("/abc"), Value: []interface{}{123}},
+			UnionOp{Path: MustNewPointerFromString("/abc"), Value: []interface{}{123}, Key: "name"},
+			SortOp{Path: MustNewPointerFromString("/abc")},
+			SortOp{Path: MustNewPointerFromString("/abc"), By: MustNewPointerFromString("/name")},
+			DedupeOp{Path: MustNewPointerFromString("/abc")},
+			DedupeOp{Path: MustNewPointerFromString("/abc"), Key: "name"},
+			MergeOp{Path: MustNewPointerFromString("/abc"), Value: 123},
+			MergeOp{Path: MustNewPointerFromString("/abc"), Value: 123, Conflict: MergeConflictKeep},
+			StrategicMergeOp{Path: MustNewPointerFromString("/abc"), Value: 123, MergeKeys: []MergeKey{{Path: MustNewPointerFromString("/jobs"), Key: "name"}}},
+			MergePatchOp{Path: MustNewPointerFromString("/abc"), Value: 123},
+			RFC6902Op{Op: "add", Path: "/abc", Value: 123},
+			RFC6902Op{Op: "move", Path: "/abc", From: "/old"},
+			RFC6902Op{Op: "remove", Path: "/abc"},
 			TestOp{Path: MustNewPointerFromString("/abc"), Value: 123},
 			TestOp{Path: MustNewPointerFromString("/abc"), Absent: true},
+			DescriptiveOp{Op: RemoveOp{Path: MustNewPointerFromString("/abc")}, ErrorMsg: "msg"},
+			Ops{ErrOp{Err: errors.New("err")}},
 		})
 
 		opDefs, err := NewOpDefinitionsFromOps(ops)
@@ -310,15 +993,83 @@ var _ = Describe("NewOpDefinitionsFromOps", func() {
   value: 123
 - type: remove
   path: /abc
+- type: move
+  from: /old
+  path: /abc
+- type: swap
+  from: /old
+  path: /abc
+- type: copy
+  from: /old
+  path: /abc
+- type: append
+  path: /abc
+  value:
+  - 123
+- type: prepend
+  path: /abc
+  value:
+  - 123
+- type: concat
+  from: /old
+  path: /abc
+- type: union
+  path: /abc
+  value:
+  - 123
+- type: union
+  path: /abc
+  value:
+  - 123
+  key: name
+- type: sort
+  path: /abc
+- type: sort
+  path: /abc
+  by: /name
+- type: dedupe
+  path: /abc
+- type: dedupe
+  path: /abc
+  key: name
+- type: merge
+  path: /abc
+  value: 123
+- type: merge
+  path: /abc
+  value: 123
+  conflict: keep
+- type: strategic_merge
+  path: /abc
+  value: 123
+  merge_keys:
+    /jobs: name
+- type: merge_patch
+  path: /abc
+  value: 123
+- type: rfc6902_add
+  path: /abc
+  value: 123
+- type: rfc6902_move
+  from: /old
+  path: /abc
+- type: rfc6902_remove
+  path: /abc
 - type: test
   path: /abc
   value: 123
 - type: test
   path: /abc
   absent: true
+- type: remove
+  path: /abc
+  error: msg
+- type: error
+  error: err
 `))
 
 		bs, err = json.MarshalIndent(opDefs, "", "    ")
+		Expect(err).ToNot(HaveOccurred())
 		Expect(string(bs)).To(Equal(`[
     {
         "Type": "replace",
@@ -329,6 +1080,111 @@ var _ = Describe("NewOpDefinitionsFromOps", func() {
         "Type": "remove",
         "Path": "/abc"
     },
+    {
+        "Type": "move",
+        "From": "/old",
+        "Path": "/abc"
+    },
+    {
+        "Type": "swap",
+        "From": "/old",
+        "Path": "/abc"
+    },
+    {
+        "Type": "copy",
+        "From": "/old",
+        "Path": "/abc"
+    },
+    {
+        "Type": "append",
+        "Path": "/abc",
+        "Value": [
+            123
+        ]
+    },
+    {
+        "Type": "prepend",
+        "Path": "/abc",
+        "Value": [
+            123
+        ]
+    },
+    {
+        "Type": "concat",
+        "From": "/old",
+        "Path": "/abc"
+    },
+    {
+        "Type": "union",
+        "Path": "/abc",
+        "Value": [
+            123
+        ]
+    },
+    {
+        "Type": "union",
+        "Path": "/abc",
+        "Value": [
+            123
+        ],
+        "Key": "name"
+    },
+    {
+        "Type": "sort",
+        "Path": "/abc"
+    },
+    {
+        "Type": "sort",
+        "Path": "/abc",
+        "By": "/name"
+    },
+    {
+        "Type": "dedupe",
+        "Path": "/abc"
+    },
+    {
+        "Type": "dedupe",
+        "Path": "/abc",
+        "Key": "name"
+    },
+    {
+        "Type": "merge",
+        "Path": "/abc",
+        "Value": 123
+    },
+    {
+        "Type": "merge",
+        "Path": "/abc",
+        "Value": 123,
+        "Conflict": "keep"
+    },
+    {
+        "Type": "strategic_merge",
+        "Path": "/abc",
+        "Value": 123,
+        "merge_keys": {
+            "/jobs": "name"
+        }
+    },
+    {
+        "Type": "merge_patch",
+        "Path": "/abc",
+        "Value": 123
+    },
+    {
+        "Type": "rfc6902_add",
+        "Path": "/abc",
+        "Value": 123
+    },
+    {
+        "Type": "rfc6902_move",
+        "From": "/old",
+        "Path": "/abc"
+    },
+    {
+        "Type": "rfc6902_remove",
+        "Path": "/abc"
+    },
     {
         "Type": "test",
         "Path": "/abc",
@@ -338,7 +1194,83 @@ var _ = Describe("NewOpDefinitionsFromOps", func() {
         "Type": "test",
         "Path": "/abc",
         "Absent": true
+    },
+    {
+        "Type": "remove",
+        "Path": "/abc",
+        "Error": "msg"
+    },
+    {
+        "Type": "error",
+        "Error": "err"
     }
 ]`))
+
+		newOps, err := NewOpsFromDefinitions(opDefs)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(newOps).To(Equal(Ops([]Op{
+			ReplaceOp{Path: MustNewPointerFromString("/abc"), Value: 123},
+			RemoveOp{Path: MustNewPointerFromString("/abc")},
+			MoveOp{Path: MustNewPointerFromString("/abc"), From: MustNewPointerFromString("/old")},
+			SwapOp{Path: MustNewPointerFromString("/abc"), From: MustNewPointerFromString("/old")},
+			CopyOp{Path: MustNewPointerFromString("/abc"), From: MustNewPointerFromString("/old")},
+			AppendOp{Path: MustNewPointerFromString("/abc"), Value: []interface{}{123}},
+			PrependOp{Path: MustNewPointerFromString("/abc"), Value: []interface{}{123}},
+			ConcatOp{Path: MustNewPointerFromString("/abc"), From: MustNewPointerFromString("/old")},
+			UnionOp{Path: MustNewPointerFromString("/abc"), Value: []interface{}{123}},
+			UnionOp{Path: MustNewPointerFromString("/abc"), Value: []interface{}{123}, Key: "name"},
+			SortOp{Path: MustNewPointerFromString("/abc")},
+			SortOp{Path: MustNewPointerFromString("/abc"), By: MustNewPointerFromString("/name")},
+			DedupeOp{Path: MustNewPointerFromString("/abc")},
+			DedupeOp{Path: MustNewPointerFromString("/abc"), Key: "name"},
+			MergeOp{Path: MustNewPointerFromString("/abc"), Value: 123},
+			MergeOp{Path: MustNewPointerFromString("/abc"), Value: 123, Conflict: MergeConflictKeep},
+			StrategicMergeOp{Path: MustNewPointerFromString("/abc"), Value: 123, MergeKeys: []MergeKey{{Path: MustNewPointerFromString("/jobs"), Key: "name"}}},
+			MergePatchOp{Path: MustNewPointerFromString("/abc"), Value: 123},
+			RFC6902Op{Op: "add", Path: "/abc", Value: 123},
+			RFC6902Op{Op: "move", Path: "/abc", From: "/old"},
+			RFC6902Op{Op: "remove", Path: "/abc"},
+			TestOp{Path: MustNewPointerFromString("/abc"), Value: 123},
+			TestOp{Path: MustNewPointerFromString("/abc"), Absent: true},
+			DescriptiveOp{Op: RemoveOp{Path: MustNewPointerFromString("/abc")}, ErrorMsg: "msg"},
+			ErrOp{Err: errors.New("err")},
+		})))
+	})
+
+	It("adds error message to all operations wrapped by descriptive operation", func() {
+		opDefs, err := NewOpDefinitionsFromOps(Ops{
+			DescriptiveOp{Op: Ops{
+				RemoveOp{Path: MustNewPointerFromString("/a")},
+				RemoveOp{Path: MustNewPointerFromString("/b")},
+			}, ErrorMsg: "msg"},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		bs, err := yaml.Marshal(opDefs)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect("\n" + string(bs)).To(Equal(`
+- type: remove
+  path: /a
+  error: msg
+- type: remove
+  path: /b
+  error: msg
+`))
+	})
+
+	It("returns error if descriptive operation wraps operation with error message", func() {
+		_, err := NewOpDefinitionsFromOps(Ops{
+			RemoveOp{Path: MustNewPointerFromString("/a")},
+			DescriptiveOp{Op: ErrOp{Err: errors.New("err")}, ErrorMsg: "msg"},
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Operation [1]: Expected described operation to not have its own error message but found 'err'"))
+	})
+
+	It("returns error for unknown operations", func() {
+		_, err := NewOpDefinitionsFromOps(Ops{FindOp{Path: MustNewPointerFromString("/a")}})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Operation [0]: Unknown operation with type 'patch.FindOp'"))
 	})
 })
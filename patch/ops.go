@@ -10,7 +10,11 @@ type Op interface {
 var _ Op = Ops{}
 var _ Op = ReplaceOp{}
 var _ Op = RemoveOp{}
+var _ Op = CopyOp{}
+var _ Op = MergePatchOp{}
+var _ Op = RFC6902Op{}
 var _ Op = FindOp{}
+var _ Op = FindAllOp{}
 var _ Op = DescriptiveOp{}
 var _ Op = ErrOp{}
 
@@ -1,10 +1,12 @@
 package patch_test
 
 import (
+	"encoding/json"
 	"errors"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v2"
 
 	. "github.com/stuart-pollock/go-patch/patch"
 )
@@ -38,3 +40,82 @@ var _ = Describe("Ops.Apply", func() {
 		Expect(err.Error()).To(ContainSubstring("fake-err"))
 	})
 })
+
+var _ = Describe("Ops serialization", func() {
+	opsFile := `
+- type: replace
+  path: /instance_groups/name=api/instances
+  value: 2
+- type: replace
+  path: /properties?/tls
+  value:
+    ca: null
+    enabled: true
+- type: move
+  from: /a
+  path: /b?
+- type: remove
+  path: /c
+  error: msg
+`
+
+	expectedOps := Ops{
+		ReplaceOp{Path: MustNewPointerFromString("/instance_groups/name=api/instances"), Value: 2},
+		ReplaceOp{Path: MustNewPointerFromString("/properties?/tls"), Value: map[interface{}]interface{}{"enabled": true, "ca": nil}},
+		MoveOp{From: MustNewPointerFromString("/a"), Path: MustNewPointerFromString("/b?")},
+		DescriptiveOp{Op: RemoveOp{Path: MustNewPointerFromString("/c")}, ErrorMsg: "msg"},
+	}
+
+	It("loads and saves operations as YAML", func() {
+		var ops Ops
+
+		err := yaml.Unmarshal([]byte(opsFile), &ops)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ops).To(Equal(expectedOps))
+
+		bs, err := yaml.Marshal(ops)
+		Expect(err).ToNot(HaveOccurred())
+		Expect("\n" + string(bs)).To(Equal(opsFile))
+	})
+
+	It("loads and saves operations as JSON", func() {
+		bs, err := json.Marshal(expectedOps)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(bs)).To(MatchJSON(`[
+  {"Type": "replace", "Path": "/instance_groups/name=api/instances", "Value": 2},
+  {"Type": "replace", "Path": "/properties?/tls", "Value": {"enabled": true, "ca": null}},
+  {"Type": "move", "From": "/a", "Path": "/b?"},
+  {"Type": "remove", "Path": "/c", "Error": "msg"}
+]`))
+
+		var ops Ops
+
+		err = json.Unmarshal(bs, &ops)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ops).To(Equal(expectedOps))
+	})
+
+	It("keeps explicit null values in JSON", func() {
+		var ops Ops
+
+		err := json.Unmarshal([]byte(`[{"type": "replace", "path": "/a", "value": null}]`), &ops)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ops).To(Equal(Ops{ReplaceOp{Path: MustNewPointerFromString("/a"), Value: nil}}))
+	})
+
+	It("returns error if operations are invalid", func() {
+		var ops Ops
+
+		err := yaml.Unmarshal([]byte(`[{type: replace}]`), &ops)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Replace operation [0]: Missing path"))
+
+		err = json.Unmarshal([]byte(`[{"type": "replace"}]`), &ops)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Replace operation [0]: Missing path"))
+
+		_, err = json.Marshal(Ops{FindOp{Path: MustNewPointerFromString("/a")}})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Operation [0]: Unknown operation with type 'patch.FindOp'"))
+	})
+})
@@ -2,6 +2,7 @@ package patch
 
 import (
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 )
@@ -9,6 +10,8 @@ import (
 var (
 	rfc6901Decoder = strings.NewReplacer("~0", "~", "~1", "/", "~7", ":")
 	rfc6901Encoder = strings.NewReplacer("~", "~0", "/", "~1", ":", "~7")
+
+	rangeRegexp = regexp.MustCompile(`^(-?\d+)?\.\.(-?\d+)?$`)
 )
 
 // More or less based on https://tools.ietf.org/html/rfc6901
@@ -40,34 +43,74 @@ func NewPointerFromString(str string) (Pointer, error) {
 	tokenStrs = tokenStrs[1:]
 
 	optional := false
-	for i, tok := range tokenStrs {
-		isLast := i == len(tokenStrs)-1
-
+	for _, tok := range tokenStrs {
 		var modifiers []Modifier
 		tokPieces := strings.Split(tok, ":")
 
+		var selections, anchors int
+
 		if len(tokPieces) > 1 {
 			tok = tokPieces[0]
 			for _, p := range tokPieces[1:] {
-				switch p {
-				case "prev":
+				switch {
+				case p == "prev":
 					modifiers = append(modifiers, PrevModifier{})
-				case "next":
+				case p == "next":
 					modifiers = append(modifiers, NextModifier{})
-				case "before":
+				case p == "before":
 					modifiers = append(modifiers, BeforeModifier{})
-				case "after":
+				case p == "after":
 					modifiers = append(modifiers, AfterModifier{})
+				case p == "all":
+					modifiers = append(modifiers, AllModifier{})
+					selections++
+				case p == "first":
+					modifiers = append(modifiers, FirstModifier{})
+					selections++
+				case p == "last":
+					modifiers = append(modifiers, LastModifier{})
+					selections++
+				case strings.HasPrefix(p, "before="):
+					preds, err := parseMatchingPredicates(strings.TrimPrefix(p, "before="))
+					if err != nil {
+						return Pointer{}, err
+					}
+					modifiers = append(modifiers, BeforeMatchingModifier{Predicates: preds})
+					anchors++
+				case strings.HasPrefix(p, "after="):
+					preds, err := parseMatchingPredicates(strings.TrimPrefix(p, "after="))
+					if err != nil {
+						return Pointer{}, err
+					}
+					modifiers = append(modifiers, AfterMatchingModifier{Predicates: preds})
+					anchors++
+				case strings.HasPrefix(p, "nth="):
+					n, err := strconv.Atoi(strings.TrimPrefix(p, "nth="))
+					if err != nil {
+						return Pointer{}, fmt.Errorf("Expected to find integer in 'nth' modifier but found '%s'", p)
+					}
+					modifiers = append(modifiers, NthModifier{N: n})
+					selections++
 				default:
-					return Pointer{}, fmt.Errorf("Expected to find one of the following modifiers: 'prev', 'next', 'before', or 'after' but found '%s'", tokPieces[1])
+					return Pointer{}, fmt.Errorf("Expected to find one of the following modifiers: 'prev', 'next', 'before', 'after', 'before=<predicates>', 'after=<predicates>', 'all', 'first', 'last', or 'nth=<n>' but found '%s'", tokPieces[1])
 				}
 			}
 		}
 
+		if selections > 1 {
+			return Pointer{}, fmt.Errorf("Expected to find at most one of 'all', 'first', 'last', or 'nth' modifiers")
+		}
+
+		if anchors > 1 {
+			return Pointer{}, fmt.Errorf("Expected to find at most one of 'before=' or 'after=' modifiers")
+		}
+
+		rawTok := tok
 		tok = rfc6901Decoder.Replace(tok)
 
-		// parse as after last index
-		if isLast && tok == "-" {
+		// parse as after last index; in the middle of a path
+		// it refers to map key '-' when applied to a map
+		if tok == "-" {
 			if len(modifiers) > 0 {
 				return Pointer{}, fmt.Errorf("Expected not to find any modifiers with after last index token")
 			}
@@ -81,27 +124,87 @@ func NewPointerFromString(str string) (Pointer, error) {
 			continue
 		}
 
+		// parse as range
+		if rangeMatch := rangeRegexp.FindStringSubmatch(tok); rangeMatch != nil {
+			if len(modifiers) > 0 {
+				return Pointer{}, fmt.Errorf("Expected not to find any modifiers with range token")
+			}
+
+			token := RangeToken{ToEnd: len(rangeMatch[2]) == 0}
+			token.Start, _ = strconv.Atoi(rangeMatch[1])
+			token.End, _ = strconv.Atoi(rangeMatch[2])
+
+			tokens = append(tokens, token)
+			continue
+		}
+
 		// parse as index
 		idx, err := strconv.Atoi(tok)
 		if err == nil {
+			if selections > 0 {
+				return Pointer{}, fmt.Errorf("Expected not to find any selection modifiers with index token")
+			}
+			if anchors > 0 {
+				return Pointer{}, fmt.Errorf("Expected not to find 'before=' or 'after=' modifiers with index token")
+			}
 			tokens = append(tokens, IndexToken{Index: idx, Modifiers: modifiers})
 			continue
 		}
 
+		// parse recursive descent; its optionality (may match nothing)
+		// does not carry over to the items to the right
+		if strings.TrimSuffix(tok, "?") == "**" {
+			if len(modifiers) > 0 {
+				return Pointer{}, fmt.Errorf("Expected not to find any modifiers with recursive descent token")
+			}
+			token := RecursiveDescentToken{
+				Optional: optional || strings.HasSuffix(tok, "?"),
+			}
+			tokens = append(tokens, token)
+			continue
+		}
+
 		if strings.HasSuffix(tok, "?") {
 			optional = true
 		}
 
-		// parse name=val
-		kv := strings.SplitN(tok, "=", 2)
-		if len(kv) == 2 {
+		// parse key glob pattern with quoted literal parts (ex: 'a='*, ''*'>1')
+		if pattern, found, err := parseQuotedGlob(strings.TrimSuffix(tok, "?")); found || err != nil {
+			if err != nil {
+				return Pointer{}, err
+			}
+
+			if strings.HasSuffix(tok, "?") {
+				return Pointer{}, fmt.Errorf("Expected not to find '?' after glob pattern '%s'", tok)
+			}
+
+			tokens = append(tokens, KeyGlobToken{Pattern: pattern})
+			continue
+		}
+
+		// parse name=val (or name=val,name2=val2, name^=val, etc.)
+		if _, _, _, found := splitMatchingPredicate(rawTok); found {
+			preds, err := parseMatchingPredicates(strings.TrimSuffix(rawTok, "?"))
+			if err != nil {
+				return Pointer{}, err
+			}
+
+			if anchors > 0 && !optional {
+				return Pointer{}, fmt.Errorf("Expected to find 'before=' or 'after=' modifiers only with optional matching index token")
+			}
+
 			token := MatchingIndexToken{
-				Key:       kv[0],
-				Value:     strings.TrimSuffix(kv[1], "?"),
+				Key:       preds[0].Key,
+				Value:     preds[0].Value,
+				Operator:  preds[0].Operator,
 				Optional:  optional,
 				Modifiers: modifiers,
 			}
 
+			if len(preds) > 1 {
+				token.Predicates = preds[1:]
+			}
+
 			tokens = append(tokens, token)
 			continue
 		}
@@ -110,6 +213,44 @@ func NewPointerFromString(str string) (Pointer, error) {
 			return Pointer{}, fmt.Errorf("Expected not to find any modifiers with key token")
 		}
 
+		// parse quoted map key (ex: '0', 'a=b', 'what?'?)
+		if strings.HasPrefix(tok, "'") {
+			key, err := unquoteWhole(strings.TrimSuffix(tok, "?"))
+			if err != nil {
+				return Pointer{}, err
+			}
+
+			tokens = append(tokens, KeyToken{Key: key, Optional: optional})
+			continue
+		}
+
+		// parse tagged map key (ex: !!int 80, !!bool true)
+		if strings.HasPrefix(tok, "!!") {
+			pieces := strings.SplitN(strings.TrimSuffix(tok, "?"), " ", 2)
+			if len(pieces) != 2 {
+				return Pointer{}, fmt.Errorf("Expected to find tag followed by a space and a map key but found '%s'", tok)
+			}
+
+			_, err := typedMapKey(pieces[0], pieces[1])
+			if err != nil {
+				return Pointer{}, err
+			}
+
+			tokens = append(tokens, KeyToken{Key: pieces[1], Optional: optional, Tag: pieces[0]})
+			continue
+		}
+
+		// parse key glob pattern; optionality does not apply
+		// since pattern may match any number of keys
+		if strings.Contains(tok, "*") {
+			if strings.HasSuffix(tok, "?") {
+				return Pointer{}, fmt.Errorf("Expected not to find '?' after glob pattern '%s'", tok)
+			}
+
+			tokens = append(tokens, KeyGlobToken{Pattern: tok})
+			continue
+		}
+
 		// it's a map key
 		token := KeyToken{
 			Key:      strings.TrimSuffix(tok, "?"),
@@ -122,6 +263,200 @@ func NewPointerFromString(str string) (Pointer, error) {
 	return Pointer{tokens}, nil
 }
 
+// parseMatchingPredicates splits compound predicate on ','
+// only if every piece is a predicate, otherwise ',' is part of the value
+func parseMatchingPredicates(str string) ([]MatchingPredicate, error) {
+	var preds []MatchingPredicate
+
+	for _, piece := range splitMatchingPredicates(str) {
+		key, op, val, _ := splitMatchingPredicate(piece)
+
+		key, err := unquoteWhole(key)
+		if err != nil {
+			return nil, err
+		}
+
+		val, err = unquoteWhole(val)
+		if err != nil {
+			return nil, err
+		}
+
+		pred := MatchingPredicate{
+			Key:      rfc6901Decoder.Replace(key),
+			Value:    rfc6901Decoder.Replace(val),
+			Operator: op,
+		}
+
+		err = pred.validate()
+		if err != nil {
+			return nil, err
+		}
+
+		preds = append(preds, pred)
+	}
+
+	return preds, nil
+}
+
+// splitMatchingPredicates splits compound predicate into pieces on ','
+// that is not quoted; returns whole string if any piece is not a predicate
+func splitMatchingPredicates(str string) []string {
+	var pieces []string
+
+	rest := str
+
+	for {
+		_, _, val, found := splitMatchingPredicate(rest)
+		if !found {
+			return []string{str}
+		}
+
+		if _, afterQuote, ok := unquote(val); ok {
+			val = afterQuote
+		}
+
+		idx := strings.Index(val, ",")
+		if idx == -1 {
+			return append(pieces, rest)
+		}
+
+		end := len(rest) - len(val) + idx
+
+		pieces = append(pieces, rest[:end])
+		rest = rest[end+1:]
+	}
+}
+
+// splitMatchingPredicate splits predicate into key, operator and value
+// based on the first found operator ('=' operator is returned as empty string)
+// that follows quoted key if there is one.
+// Comparison operators require non-empty key (ex: '<<' is a map key).
+func splitMatchingPredicate(str string) (string, string, string, bool) {
+	var start int
+
+	if _, afterQuote, ok := unquote(str); ok {
+		start = len(str) - len(afterQuote)
+	}
+
+	idx := strings.IndexAny(str[start:], "=<>")
+	if idx == -1 {
+		return "", "", "", false
+	}
+
+	idx += start
+
+	key := str[:idx]
+
+	if str[idx] == '=' {
+		if idx > 0 && strings.ContainsAny(str[idx-1:idx], "!~^$") {
+			return str[:idx-1], str[idx-1 : idx+1], str[idx+1:], true
+		}
+		return key, "", str[idx+1:], true
+	}
+
+	if len(key) == 0 {
+		return "", "", "", false
+	}
+
+	op := str[idx : idx+1]
+	if strings.HasPrefix(str[idx+1:], "=") {
+		op += "="
+	}
+
+	return key, op, str[idx+len(op):], true
+}
+
+// parseQuotedGlob parses glob pattern that starts with a quoted part
+// and consists of quoted literal parts and unquoted '*' (ex: 'a='*'b');
+// returns false if string is not such a pattern
+func parseQuotedGlob(str string) (string, bool, error) {
+	if !strings.HasPrefix(str, "'") {
+		return "", false, nil
+	}
+
+	var pattern string
+	var wildcards int
+	var quotedWildcard bool
+
+	for rest := str; len(rest) > 0; {
+		switch {
+		case strings.HasPrefix(rest, "*"):
+			pattern += "*"
+			wildcards++
+			rest = rest[1:]
+
+		case strings.HasPrefix(rest, "'"):
+			literal, afterQuote, ok := unquote(rest)
+			if !ok {
+				return "", false, nil
+			}
+			quotedWildcard = quotedWildcard || strings.Contains(literal, "*")
+			pattern += literal
+			rest = afterQuote
+
+		default:
+			return "", false, nil
+		}
+	}
+
+	// Single quoted part is a map key (ex: 'a*')
+	if wildcards == 0 {
+		return "", false, nil
+	}
+
+	if quotedWildcard {
+		return "", false, fmt.Errorf("Expected not to find '*' in quoted part of glob pattern '%s'", str)
+	}
+
+	return pattern, true, nil
+}
+
+// unquote returns contents of a string starting with a single quote
+// (two single quotes stand for one) and the rest of the string after closing quote
+func unquote(str string) (string, string, bool) {
+	if !strings.HasPrefix(str, "'") {
+		return "", "", false
+	}
+
+	var unquoted []byte
+
+	for i := 1; i < len(str); i++ {
+		if str[i] == '\'' {
+			if i+1 < len(str) && str[i+1] == '\'' {
+				unquoted = append(unquoted, '\'')
+				i++
+				continue
+			}
+			return string(unquoted), str[i+1:], true
+		}
+		unquoted = append(unquoted, str[i])
+	}
+
+	return "", "", false
+}
+
+// unquoteWhole returns contents of a quoted string or the string itself if it's not quoted
+func unquoteWhole(str string) (string, error) {
+	if !strings.HasPrefix(str, "'") {
+		return str, nil
+	}
+
+	unquoted, rest, ok := unquote(str)
+	if !ok {
+		return "", fmt.Errorf("Expected to find closing quote in '%s'", str)
+	}
+
+	if len(rest) > 0 {
+		return "", fmt.Errorf("Expected to find nothing after closing quote in '%s' but found '%s'", str, rest)
+	}
+
+	return unquoted, nil
+}
+
+func quote(str string) string {
+	return "'" + strings.Replace(str, "'", "''", -1) + "'"
+}
+
 func NewPointer(tokens []Token) Pointer {
 	if len(tokens) == 0 {
 		panic("Expected at least one token")
@@ -155,25 +490,66 @@ func (p Pointer) String() string {
 		case WildcardToken:
 			strs = append(strs, "*")
 
+		case RangeToken:
+			var start, end string
+
+			if typedToken.Start != 0 {
+				start = strconv.Itoa(typedToken.Start)
+			}
+			if !typedToken.ToEnd {
+				end = strconv.Itoa(typedToken.End)
+			}
+
+			strs = append(strs, start+".."+end)
+
+		case KeyGlobToken:
+			str := rfc6901Encoder.Replace(typedToken.Pattern)
+
+			if !p.isPlainGlob(typedToken.Pattern) {
+				pieces := strings.Split(str, "*")
+				for i, piece := range pieces {
+					if i == 0 || len(piece) > 0 {
+						pieces[i] = quote(piece)
+					}
+				}
+				str = strings.Join(pieces, "*")
+			}
+
+			strs = append(strs, str)
+
+		case RecursiveDescentToken:
+			str := "**"
+
+			if typedToken.Optional && !optional {
+				str += "?"
+			}
+
+			strs = append(strs, str)
+
 		case AfterLastIndexToken:
 			strs = append(strs, "-")
 
 		case MatchingIndexToken:
-			key := rfc6901Encoder.Replace(typedToken.Key)
-			val := rfc6901Encoder.Replace(typedToken.Value)
+			str := p.predicatesString(matchingPredicates(typedToken))
 
 			if typedToken.Optional {
 				if !optional {
-					val += "?"
+					str += "?"
 					optional = true
 				}
 			}
 
-			strs = append(strs, fmt.Sprintf("%s=%s%s", key, val, p.modifiersString(typedToken.Modifiers)))
+			strs = append(strs, str+p.modifiersString(typedToken.Modifiers))
 
 		case KeyToken:
 			str := rfc6901Encoder.Replace(typedToken.Key)
 
+			if len(typedToken.Tag) > 0 {
+				str = typedToken.Tag + " " + str
+			} else if !p.isPlainKey(typedToken.Key) {
+				str = quote(str)
+			}
+
 			if typedToken.Optional { // /key?/key2/key3
 				if !optional {
 					str += "?"
@@ -191,11 +567,89 @@ func (p Pointer) String() string {
 	return strings.Join(strs, "/")
 }
 
-func (Pointer) modifiersString(modifiers []Modifier) string {
+func (p Pointer) predicatesString(preds []MatchingPredicate) string {
+	var strs []string
+
+	for _, pred := range preds {
+		key := rfc6901Encoder.Replace(pred.Key)
+		val := rfc6901Encoder.Replace(pred.Value)
+
+		if p.needsQuotedPredicateKey(key, pred.Operator) {
+			key = quote(key)
+		}
+
+		op := pred.Operator
+		if len(op) == 0 {
+			op = "="
+		}
+
+		if p.needsQuotedPredicateValue(val, len(preds) > 1) {
+			val = quote(val)
+		}
+
+		strs = append(strs, key+op+val)
+	}
+
+	return strings.Join(strs, ",")
+}
+
+// isPlainKey checks if key would be parsed back as a key token without quoting
+func (Pointer) isPlainKey(key string) bool {
+	ptr, err := NewPointerFromString("/" + rfc6901Encoder.Replace(key))
+	if err != nil || len(ptr.tokens) != 2 {
+		return false
+	}
+
+	keyToken, ok := ptr.tokens[1].(KeyToken)
+
+	return ok && keyToken == KeyToken{Key: key}
+}
+
+// isPlainGlob checks if glob pattern would be parsed back as is without quoting
+func (Pointer) isPlainGlob(pattern string) bool {
+	ptr, err := NewPointerFromString("/" + rfc6901Encoder.Replace(pattern))
+	if err != nil || len(ptr.tokens) != 2 {
+		return false
+	}
+
+	globToken, ok := ptr.tokens[1].(KeyGlobToken)
+
+	return ok && globToken == KeyGlobToken{Pattern: pattern}
+}
+
+// needsQuotedPredicateKey checks if predicate key contains operators
+// or ends with a character that could be combined with '=' into an operator
+func (Pointer) needsQuotedPredicateKey(key, op string) bool {
+	switch {
+	case strings.HasPrefix(key, "'") || strings.ContainsAny(key, "=<>,"):
+		return true
+	case len(key) > 0 && strings.ContainsAny(key[len(key)-1:], "!~^$"):
+		return true
+	case len(key) == 0 && strings.ContainsAny(op, "<>"):
+		return true
+	}
+
+	return false
+}
+
+// needsQuotedPredicateValue checks if predicate value could be mistaken for
+// quoted value, optionality marker or another predicate
+func (Pointer) needsQuotedPredicateValue(val string, compound bool) bool {
+	switch {
+	case strings.HasPrefix(val, "'") || strings.HasSuffix(val, "?"):
+		return true
+	case strings.Contains(val, ","):
+		return compound || len(splitMatchingPredicates("="+val)) > 1
+	}
+
+	return false
+}
+
+func (p Pointer) modifiersString(modifiers []Modifier) string {
 	var str string
 	for _, modifier := range modifiers {
 		str += ":"
-		switch modifier.(type) {
+		switch typedModifier := modifier.(type) {
 		case PrevModifier:
 			str += "prev"
 		case NextModifier:
@@ -204,6 +658,18 @@ func (Pointer) modifiersString(modifiers []Modifier) string {
 			str += "before"
 		case AfterModifier:
 			str += "after"
+		case BeforeMatchingModifier:
+			str += "before=" + p.predicatesString(typedModifier.Predicates)
+		case AfterMatchingModifier:
+			str += "after=" + p.predicatesString(typedModifier.Predicates)
+		case AllModifier:
+			str += "all"
+		case FirstModifier:
+			str += "first"
+		case LastModifier:
+			str += "last"
+		case NthModifier:
+			str += fmt.Sprintf("nth=%d", typedModifier.N)
 		}
 	}
 	return str
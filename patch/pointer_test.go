@@ -40,7 +40,9 @@ var testCases = []PointerTestCase{
 
 	{"/-", []Token{RootToken{}, AfterLastIndexToken{}}},
 	{"/ary/-", []Token{RootToken{}, KeyToken{Key: "ary"}, AfterLastIndexToken{}}},
-	{"/-/key", []Token{RootToken{}, KeyToken{Key: "-"}, KeyToken{Key: "key"}}},
+	{"/-/key", []Token{RootToken{}, AfterLastIndexToken{}, KeyToken{Key: "key"}}},
+	{"/ary/-/-", []Token{RootToken{}, KeyToken{Key: "ary"}, AfterLastIndexToken{}, AfterLastIndexToken{}}},
+	{"/'-'/key", []Token{RootToken{}, KeyToken{Key: "-"}, KeyToken{Key: "key"}}},
 
 	{"/0:before", []Token{
 		RootToken{},
@@ -59,6 +61,42 @@ var testCases = []PointerTestCase{
 		IndexToken{Index: -1, Modifiers: []Modifier{PrevModifier{}, BeforeModifier{}}},
 	}},
 
+	// Array ranges
+	{"/2..5", []Token{RootToken{}, RangeToken{Start: 2, End: 5}}},
+	{"/..3", []Token{RootToken{}, RangeToken{End: 3}}},
+	{"/-2..", []Token{RootToken{}, RangeToken{Start: -2, ToEnd: true}}},
+	{"/..-1", []Token{RootToken{}, RangeToken{End: -1}}},
+	{"/..", []Token{RootToken{}, RangeToken{ToEnd: true}}},
+	{"/ary/1..", []Token{RootToken{}, KeyToken{Key: "ary"}, RangeToken{Start: 1, ToEnd: true}}},
+
+	// Wildcards and key glob patterns
+	{"/*", []Token{RootToken{}, WildcardToken{}}},
+	{"/*/key", []Token{RootToken{}, WildcardToken{}, KeyToken{Key: "key"}}},
+	{"/*_api", []Token{RootToken{}, KeyGlobToken{Pattern: "*_api"}}},
+	{"/a*b*", []Token{RootToken{}, KeyGlobToken{Pattern: "a*b*"}}},
+	{"/'a='*", []Token{RootToken{}, KeyGlobToken{Pattern: "a=*"}}},
+	{"/'a'*'>1'", []Token{RootToken{}, KeyGlobToken{Pattern: "a*>1"}}},
+	{"/'a'*'?'", []Token{RootToken{}, KeyGlobToken{Pattern: "a*?"}}},
+	{"/'''a'*", []Token{RootToken{}, KeyGlobToken{Pattern: "'a*"}}},
+	{"/''*'=a'", []Token{RootToken{}, KeyGlobToken{Pattern: "*=a"}}},
+	{"/''**", []Token{RootToken{}, KeyGlobToken{Pattern: "**"}}},
+	{"/'!!int '*", []Token{RootToken{}, KeyGlobToken{Pattern: "!!int *"}}},
+	{"/a~1b~7*", []Token{RootToken{}, KeyGlobToken{Pattern: "a/b:*"}}},
+
+	// Recursive descent
+	{"/**", []Token{RootToken{}, RecursiveDescentToken{}}},
+	{"/**/key", []Token{RootToken{}, RecursiveDescentToken{}, KeyToken{Key: "key"}}},
+	{"/**?/key", []Token{
+		RootToken{},
+		RecursiveDescentToken{Optional: true},
+		KeyToken{Key: "key"},
+	}},
+	{"/key?/**", []Token{
+		RootToken{},
+		KeyToken{Key: "key", Optional: true},
+		RecursiveDescentToken{Optional: true},
+	}},
+
 	// Matching index token
 	{"/name=val", []Token{RootToken{}, MatchingIndexToken{Key: "name", Value: "val"}}},
 	{"/name=val?", []Token{RootToken{}, MatchingIndexToken{Key: "name", Value: "val", Optional: true}}},
@@ -73,6 +111,53 @@ var testCases = []PointerTestCase{
 	{"/=val", []Token{RootToken{}, MatchingIndexToken{Key: "", Value: "val"}}},
 	{"/==", []Token{RootToken{}, MatchingIndexToken{Key: "", Value: "="}}},
 
+	{"/name=val,az=z1", []Token{RootToken{}, MatchingIndexToken{
+		Key:        "name",
+		Value:      "val",
+		Predicates: []MatchingPredicate{{Key: "az", Value: "z1"}},
+	}}},
+	{"/name=val,az=z1,release.name=capi?:first", []Token{RootToken{}, MatchingIndexToken{
+		Key:   "name",
+		Value: "val",
+		Predicates: []MatchingPredicate{
+			{Key: "az", Value: "z1"},
+			{Key: "release.name", Value: "capi"},
+		},
+		Optional:  true,
+		Modifiers: []Modifier{FirstModifier{}},
+	}}},
+	{"/name=val,val2", []Token{RootToken{}, MatchingIndexToken{Key: "name", Value: "val,val2"}}},
+	{"/properties~1tls~1enabled=true", []Token{RootToken{}, MatchingIndexToken{Key: "properties/tls/enabled", Value: "true"}}},
+
+	{"/name=!!str 0", []Token{RootToken{}, MatchingIndexToken{Key: "name", Value: "!!str 0"}}},
+	{"/instances=0,enabled=true", []Token{RootToken{}, MatchingIndexToken{
+		Key:        "instances",
+		Value:      "0",
+		Predicates: []MatchingPredicate{{Key: "enabled", Value: "true"}},
+	}}},
+
+	{"/name!=val", []Token{RootToken{}, MatchingIndexToken{Key: "name", Value: "val", Operator: "!="}}},
+	{"/name~=^api-[0-9]+$", []Token{RootToken{}, MatchingIndexToken{Key: "name", Value: "^api-[0-9]+$", Operator: "~="}}},
+	{"/name^=errand-:all", []Token{RootToken{}, MatchingIndexToken{
+		Key:       "name",
+		Value:     "errand-",
+		Operator:  "^=",
+		Modifiers: []Modifier{AllModifier{}},
+	}}},
+	{"/name$=-worker,instances>0,instances<=10,lifecycle!=*,env=*", []Token{RootToken{}, MatchingIndexToken{
+		Key:      "name",
+		Value:    "-worker",
+		Operator: "$=",
+		Predicates: []MatchingPredicate{
+			{Key: "instances", Value: "0", Operator: ">"},
+			{Key: "instances", Value: "10", Operator: "<="},
+			{Key: "lifecycle", Value: "*", Operator: "!="},
+			{Key: "env", Value: "*"},
+		},
+	}}},
+	{"/a~0=b", []Token{RootToken{}, MatchingIndexToken{Key: "a~", Value: "b"}}},
+	{"/<<", []Token{RootToken{}, KeyToken{Key: "<<"}}},
+
 	{"/name=val:before", []Token{
 		RootToken{},
 		MatchingIndexToken{Key: "name", Value: "val", Modifiers: []Modifier{BeforeModifier{}}},
@@ -82,6 +167,37 @@ var testCases = []PointerTestCase{
 		MatchingIndexToken{Key: "name", Value: "val", Modifiers: []Modifier{AfterModifier{}}},
 	}},
 
+	{"/name=val:all", []Token{
+		RootToken{},
+		MatchingIndexToken{Key: "name", Value: "val", Modifiers: []Modifier{AllModifier{}}},
+	}},
+	{"/name=val:first", []Token{
+		RootToken{},
+		MatchingIndexToken{Key: "name", Value: "val", Modifiers: []Modifier{FirstModifier{}}},
+	}},
+	{"/name=val:last:before", []Token{
+		RootToken{},
+		MatchingIndexToken{Key: "name", Value: "val", Modifiers: []Modifier{LastModifier{}, BeforeModifier{}}},
+	}},
+	{"/name=val:nth=-2:after", []Token{
+		RootToken{},
+		MatchingIndexToken{Key: "name", Value: "val", Modifiers: []Modifier{NthModifier{N: -2}, AfterModifier{}}},
+	}},
+
+	{"/name=foo?:before=name=bar", []Token{
+		RootToken{},
+		MatchingIndexToken{Key: "name", Value: "foo", Optional: true, Modifiers: []Modifier{
+			BeforeMatchingModifier{Predicates: []MatchingPredicate{{Key: "name", Value: "bar"}}},
+		}},
+	}},
+	{"/name=foo?:first:after=name^=bar,az=z1", []Token{
+		RootToken{},
+		MatchingIndexToken{Key: "name", Value: "foo", Optional: true, Modifiers: []Modifier{
+			FirstModifier{},
+			AfterMatchingModifier{Predicates: []MatchingPredicate{{Key: "name", Value: "bar", Operator: "^="}, {Key: "az", Value: "z1"}}},
+		}},
+	}},
+
 	// Optionality
 	{"/key?/name=val", []Token{
 		RootToken{},
@@ -94,13 +210,58 @@ var testCases = []PointerTestCase{
 		KeyToken{Key: "key", Optional: true},
 	}},
 
-	// Escaping (todo support ~2 for '?'; ~3 for '=')
+	// Escaping
 	{"/m~0n", []Token{RootToken{}, KeyToken{Key: "m~n"}}},
 	{"/a~01b", []Token{RootToken{}, KeyToken{Key: "a~1b"}}},
 	{"/a~1b", []Token{RootToken{}, KeyToken{Key: "a/b"}}},
 	{"/name~0n=val~0n", []Token{RootToken{}, MatchingIndexToken{Key: "name~n", Value: "val~n"}}},
 	{"/m~7n", []Token{RootToken{}, KeyToken{Key: "m:n"}}},
 
+	// Tagged map keys
+	{"/!!int 80", []Token{RootToken{}, KeyToken{Key: "80", Tag: "!!int"}}},
+	{"/!!bool true?/!!float 1.5", []Token{
+		RootToken{},
+		KeyToken{Key: "true", Tag: "!!bool", Optional: true},
+		KeyToken{Key: "1.5", Tag: "!!float", Optional: true},
+	}},
+	{"/!!str a~1b", []Token{RootToken{}, KeyToken{Key: "a/b", Tag: "!!str"}}},
+	{"/'!!int 80'", []Token{RootToken{}, KeyToken{Key: "!!int 80"}}},
+
+	// Quoting
+	{"/'0'", []Token{RootToken{}, KeyToken{Key: "0"}}},
+	{"/ports/'8080'/'-1'", []Token{RootToken{}, KeyToken{Key: "ports"}, KeyToken{Key: "8080"}, KeyToken{Key: "-1"}}},
+	{"/'-'", []Token{RootToken{}, KeyToken{Key: "-"}}},
+	{"/'1..2'", []Token{RootToken{}, KeyToken{Key: "1..2"}}},
+	{"/'*'", []Token{RootToken{}, KeyToken{Key: "*"}}},
+	{"/'**'", []Token{RootToken{}, KeyToken{Key: "**"}}},
+	{"/'a*b'", []Token{RootToken{}, KeyToken{Key: "a*b"}}},
+	{"/'a=b'", []Token{RootToken{}, KeyToken{Key: "a=b"}}},
+	{"/'a>b'", []Token{RootToken{}, KeyToken{Key: "a>b"}}},
+	{"/'what?'", []Token{RootToken{}, KeyToken{Key: "what?"}}},
+	{"/'what?'?/key", []Token{
+		RootToken{},
+		KeyToken{Key: "what?", Optional: true},
+		KeyToken{Key: "key", Optional: true},
+	}},
+	{"/'''quoted'''", []Token{RootToken{}, KeyToken{Key: "'quoted'"}}},
+	{"/it's", []Token{RootToken{}, KeyToken{Key: "it's"}}},
+	{"/'a~1b~7c=d'", []Token{RootToken{}, KeyToken{Key: "a/b:c=d"}}},
+	{"/'a=b'=c", []Token{RootToken{}, MatchingIndexToken{Key: "a=b", Value: "c"}}},
+	{"/'a!'=c", []Token{RootToken{}, MatchingIndexToken{Key: "a!", Value: "c"}}},
+	{"/'a^'^=c", []Token{RootToken{}, MatchingIndexToken{Key: "a^", Value: "c", Operator: "^="}}},
+	{"/'a<b'=c", []Token{RootToken{}, MatchingIndexToken{Key: "a<b", Value: "c"}}},
+	{"/'a>'>1", []Token{RootToken{}, MatchingIndexToken{Key: "a>", Value: "1", Operator: ">"}}},
+	{"/''<1", []Token{RootToken{}, MatchingIndexToken{Key: "", Value: "1", Operator: "<"}}},
+	{"/name='val?'", []Token{RootToken{}, MatchingIndexToken{Key: "name", Value: "val?"}}},
+	{"/name='val?'?", []Token{RootToken{}, MatchingIndexToken{Key: "name", Value: "val?", Optional: true}}},
+	{"/name='a,b=c'", []Token{RootToken{}, MatchingIndexToken{Key: "name", Value: "a,b=c"}}},
+	{"/name='a,b',az=z1", []Token{RootToken{}, MatchingIndexToken{
+		Key:        "name",
+		Value:      "a,b",
+		Predicates: []MatchingPredicate{{Key: "az", Value: "z1"}},
+	}}},
+	{"/name='''val'''", []Token{RootToken{}, MatchingIndexToken{Key: "name", Value: "'val'"}}},
+
 	// Special chars
 	{"/c%d", []Token{RootToken{}, KeyToken{Key: "c%d"}}},
 	{"/e^f", []Token{RootToken{}, KeyToken{Key: "e^f"}}},
@@ -133,7 +294,39 @@ var _ = Describe("NewPointerFromString", func() {
 	It("returns error if string includes unknown modifiers", func() {
 		_, err := NewPointerFromString("/abc:unknown")
 		Expect(err).To(HaveOccurred())
-		Expect(err.Error()).To(Equal("Expected to find one of the following modifiers: 'prev', 'next', 'before', or 'after' but found 'unknown'"))
+		Expect(err.Error()).To(Equal("Expected to find one of the following modifiers: 'prev', 'next', 'before', 'after', 'before=<predicates>', 'after=<predicates>', 'all', 'first', 'last', or 'nth=<n>' but found 'unknown'"))
+	})
+
+	It("returns error if string includes invalid nth modifier", func() {
+		_, err := NewPointerFromString("/name=val:nth=x")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find integer in 'nth' modifier but found 'nth=x'"))
+	})
+
+	It("returns error if string includes multiple selection modifiers", func() {
+		_, err := NewPointerFromString("/name=val:first:last")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find at most one of 'all', 'first', 'last', or 'nth' modifiers"))
+	})
+
+	It("returns error if string has invalid 'before=' or 'after=' modifiers", func() {
+		_, err := NewPointerFromString("/name=foo:before=name=bar")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find 'before=' or 'after=' modifiers only with optional matching index token"))
+
+		_, err = NewPointerFromString("/name=foo?:before=name=bar:after=name=baz")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find at most one of 'before=' or 'after=' modifiers"))
+
+		_, err = NewPointerFromString("/0:before=name=bar")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected not to find 'before=' or 'after=' modifiers with index token"))
+	})
+
+	It("returns error if string has selection modifiers in index token", func() {
+		_, err := NewPointerFromString("/0:first")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected not to find any selection modifiers with index token"))
 	})
 
 	It("returns error if string has modifiers in after-last-index-token", func() {
@@ -142,6 +335,64 @@ var _ = Describe("NewPointerFromString", func() {
 		Expect(err.Error()).To(Equal("Expected not to find any modifiers with after last index token"))
 	})
 
+	It("returns error if string has modifiers in recursive descent token", func() {
+		_, err := NewPointerFromString("/**:prev")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected not to find any modifiers with recursive descent token"))
+	})
+
+	It("returns error if string has modifiers in range token", func() {
+		_, err := NewPointerFromString("/1..2:prev")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected not to find any modifiers with range token"))
+	})
+
+	It("returns error if predicate cannot be parsed", func() {
+		_, err := NewPointerFromString("/name~=api-(")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find valid regular expression in predicate 'name~=api-(': error parsing regexp: missing closing ): `api-(`"))
+
+		_, err = NewPointerFromString("/name=api,instances>=a")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find number in predicate 'instances>=a' but found 'a'"))
+	})
+
+	It("returns error if quoted key or value is not terminated", func() {
+		_, err := NewPointerFromString("/'key")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find closing quote in ''key'"))
+
+		_, err = NewPointerFromString("/'key'a")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find nothing after closing quote in ''key'a' but found 'a'"))
+
+		_, err = NewPointerFromString("/'a*'*")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected not to find '*' in quoted part of glob pattern ''a*'*'"))
+
+		_, err = NewPointerFromString("/a*?")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected not to find '?' after glob pattern 'a*?'"))
+
+		_, err = NewPointerFromString("/'a='*?")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected not to find '?' after glob pattern ''a='*?'"))
+
+		_, err = NewPointerFromString("/name='val'a")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find nothing after closing quote in ''val'a' but found 'a'"))
+	})
+
+	It("returns error if tagged map key is not valid", func() {
+		_, err := NewPointerFromString("/!!int")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find tag followed by a space and a map key but found '!!int'"))
+
+		_, err = NewPointerFromString("/!!int abc")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Expected to find valid '!!int' map key but found 'abc': "))
+	})
+
 	It("returns error if string has modifiers in key-token", func() {
 		_, err := NewPointerFromString("/key:prev")
 		Expect(err).To(HaveOccurred())
@@ -165,6 +416,7 @@ var _ = Describe("Pointer.Tokens", func() {
 			KeyToken{Key: "key"},
 			KeyToken{Key: "key2", Optional: true},
 		}},
+		{"/0..3", []Token{RootToken{}, RangeToken{End: 3}}},
 	}
 
 	parsingTestCases = append(parsingTestCases, testCases...)
@@ -0,0 +1,23 @@
+package patch
+
+// PrependOp inserts all items of the array value
+// at the beginning of the array found at the path (keeping their order).
+// Missing optional arrays are created (ex: /instance_groups/name=api/jobs?).
+type PrependOp struct {
+	Path  Pointer
+	Value interface{} // will be cloned using yaml library
+}
+
+func (op PrependOp) Apply(doc interface{}) (interface{}, error) {
+	items, err := arrayValue(op.Value, "prepend", op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	return arrayUpdate{
+		Path: op.Path,
+		Update: func(array []interface{}, _ Pointer) ([]interface{}, error) {
+			return append(append([]interface{}{}, items...), array...), nil
+		},
+	}.Apply(doc)
+}
@@ -0,0 +1,38 @@
+package patch_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/stuart-pollock/go-patch/patch"
+)
+
+var _ = Describe("PrependOp.Apply", func() {
+	It("inserts all items at the beginning of the array keeping their order", func() {
+		doc := map[interface{}]interface{}{
+			"releases": []interface{}{"c"},
+		}
+
+		res, err := PrependOp{Path: MustNewPointerFromString("/releases"), Value: []interface{}{"a", "b"}}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(map[interface{}]interface{}{
+			"releases": []interface{}{"a", "b", "c"},
+		}))
+	})
+
+	It("creates missing optional arrays", func() {
+		res, err := PrependOp{Path: MustNewPointerFromString("/releases?"), Value: []interface{}{"a"}}.Apply(map[interface{}]interface{}{})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(map[interface{}]interface{}{
+			"releases": []interface{}{"a"},
+		}))
+	})
+
+	It("returns an error if value is not an array", func() {
+		_, err := PrependOp{Path: MustNewPointerFromString("/items"), Value: 1}.Apply(map[interface{}]interface{}{"items": []interface{}{}})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected value to be an array to prepend at path '/items' but found 'int'"))
+	})
+})
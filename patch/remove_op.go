@@ -15,6 +15,10 @@ func (op RemoveOp) Apply(doc interface{}) (interface{}, error) {
 		return nil, fmt.Errorf("Cannot remove entire document")
 	}
 
+	if isRecursive(op.Path) {
+		return op.applyRecursive(doc)
+	}
+
 	ctxStack := []*mutationCtx{&mutationCtx{
 		PrevUpdate: func(newObj interface{}) { doc = newObj },
 		I:          0,
@@ -34,6 +38,8 @@ func (op RemoveOp) Apply(doc interface{}) (interface{}, error) {
 		isLast := ctx.I == len(tokens)-2
 		currPath := NewPointer(tokens[:ctx.I+2])
 
+		token = afterLastIndexKey(token, ctx.Obj, isLast)
+
 		switch typedToken := token.(type) {
 		case IndexToken:
 			idx := typedToken.Index
@@ -61,82 +67,124 @@ func (op RemoveOp) Apply(doc interface{}) (interface{}, error) {
 				})
 			}
 
-		case MatchingIndexToken:
+		case RangeToken:
 			typedObj, ok := ctx.Obj.([]interface{})
 			if !ok {
 				return nil, NewOpArrayMismatchTypeErr(currPath, ctx.Obj)
 			}
 
-			var idxs []int
+			if !isLast {
+				return nil, fmt.Errorf("Expected range token to be last in path '%s'", op.Path)
+			}
 
-			for itemIdx, item := range typedObj {
-				typedItem, ok := item.(map[interface{}]interface{})
-				if ok {
-					if typedItem[typedToken.Key] == typedToken.Value {
-						idxs = append(idxs, itemIdx)
-					}
-				}
+			start, end, err := ArrayRange{Start: typedToken.Start, End: typedToken.End, ToEnd: typedToken.ToEnd, Array: typedObj, Path: currPath}.Concrete()
+			if err != nil {
+				return nil, err
 			}
 
-			if typedToken.Optional && len(idxs) == 0 {
-				continue // don't exit early
+			newAry := []interface{}{}
+			newAry = append(newAry, typedObj[:start]...)
+			newAry = append(newAry, typedObj[end:]...)
+			ctx.PrevUpdate(newAry)
+
+		case MatchingIndexToken:
+			typedObj, ok := ctx.Obj.([]interface{})
+			if !ok {
+				return nil, NewOpArrayMismatchTypeErr(currPath, ctx.Obj)
 			}
 
-			if len(idxs) != 1 {
-				return nil, OpMultipleMatchingIndexErr{currPath, idxs}
+			idxs := matchingIndexes(typedToken, typedObj)
+
+			if typedToken.Optional && len(idxs) == 0 {
+				continue // don't exit early
 			}
 
-			idx, err := ArrayIndex{Index: idxs[0], Modifiers: typedToken.Modifiers, Array: typedObj, Path: currPath}.Concrete()
+			idxs, modifiers, err := selectMatchingIndexes(typedToken, idxs, currPath)
 			if err != nil {
 				return nil, err
 			}
 
+			removedIdxs := map[int]struct{}{}
+
+			for _, idx := range idxs {
+				idx, err := ArrayIndex{Index: idx, Modifiers: modifiers, Array: typedObj, Path: currPath}.Concrete()
+				if err != nil {
+					return nil, err
+				}
+
+				if isLast {
+					removedIdxs[idx] = struct{}{}
+				} else {
+					ctxStack = append(ctxStack, &mutationCtx{
+						Obj:        typedObj[idx],
+						PrevUpdate: func(newObj interface{}) { typedObj[idx] = newObj },
+						I:          ctx.I + 1,
+					})
+				}
+			}
+
 			if isLast {
 				newAry := []interface{}{}
-				newAry = append(newAry, typedObj[:idx]...)
-				newAry = append(newAry, typedObj[idx+1:]...)
+				for idx, item := range typedObj {
+					if _, found := removedIdxs[idx]; !found {
+						newAry = append(newAry, item)
+					}
+				}
 				ctx.PrevUpdate(newAry)
-			} else {
-				ctxStack = append(ctxStack, &mutationCtx{
-					Obj:        typedObj[idx],
-					PrevUpdate: ctx.PrevUpdate, // no need to change prevUpdate since matching item can only be a map
-					I:          ctx.I + 1,
-				})
 			}
 
 		case KeyToken:
-			typedObj, ok := ctx.Obj.(map[interface{}]interface{})
+			typedObj, ok := newGenericMap(ctx.Obj)
 			if !ok {
 				return nil, NewOpMapMismatchTypeErr(currPath, ctx.Obj)
 			}
 
-			o, found := typedObj[typedToken.Key]
+			o, found := typedObj.Get(typedToken.mapKey())
 			if !found {
 				if typedToken.Optional {
 					continue // don't return yet, as it may be present down alternate paths
 				}
 
-				return nil, OpMissingMapKeyErr{typedToken.Key, currPath, typedObj}
+				return nil, newOpMissingMapKeyErr(typedToken.Key, currPath, typedObj)
 			}
 
 			if isLast {
-				delete(typedObj, typedToken.Key)
+				typedObj.Delete(typedToken.mapKey())
 			} else {
 				ctxStack = append(ctxStack, &mutationCtx{
 					Obj:        o,
-					PrevUpdate: func(newObj interface{}) { typedObj[typedToken.Key] = newObj },
+					PrevUpdate: func(newObj interface{}) { typedObj.Set(typedToken.mapKey(), newObj) },
 					I:          ctx.I + 1,
 				})
 			}
 
 		case WildcardToken:
+			// Wildcard over map keys behaves the same as '*' glob pattern
+			if typedObj, ok := newGenericMap(ctx.Obj); ok {
+				for _, key := range sortedKeys(typedObj) {
+					key := key
+
+					if isLast {
+						typedObj.Delete(key)
+					} else {
+						o, _ := typedObj.Get(key)
+						ctxStack = append(ctxStack, &mutationCtx{
+							PrevUpdate: func(newObj interface{}) { typedObj.Set(key, newObj) },
+							I:          ctx.I + 1,
+							Obj:        o,
+						})
+					}
+				}
+				break
+			}
+
 			if isLast {
 				return nil, fmt.Errorf("Wildcard must not be the last token")
 			}
 
 			typedObj, ok := ctx.Obj.([]interface{})
 			if !ok {
-				return nil, NewOpArrayMismatchTypeErr(currPath, ctx.Obj)
+				return nil, OpMismatchTypeErr{"a map or an array", currPath, ctx.Obj}
 			}
 
 			for idx, o := range typedObj {
@@ -147,6 +195,27 @@ func (op RemoveOp) Apply(doc interface{}) (interface{}, error) {
 				})
 			}
 
+		case KeyGlobToken:
+			typedObj, ok := newGenericMap(ctx.Obj)
+			if !ok {
+				return nil, NewOpMapMismatchTypeErr(currPath, ctx.Obj)
+			}
+
+			for _, key := range matchingKeys(typedToken, typedObj) {
+				key := key
+
+				if isLast {
+					typedObj.Delete(key)
+				} else {
+					o, _ := typedObj.Get(key)
+					ctxStack = append(ctxStack, &mutationCtx{
+						Obj:        o,
+						PrevUpdate: func(newObj interface{}) { typedObj.Set(key, newObj) },
+						I:          ctx.I + 1,
+					})
+				}
+			}
+
 		default:
 			return nil, OpUnexpectedTokenErr{token, currPath}
 		}
@@ -154,3 +223,27 @@ func (op RemoveOp) Apply(doc interface{}) (interface{}, error) {
 
 	return doc, nil
 }
+
+// applyRecursive removes every value matched by the path
+func (op RemoveOp) applyRecursive(doc interface{}) (interface{}, error) {
+	tokens := op.Path.Tokens()
+
+	if _, ok := tokens[len(tokens)-1].(RecursiveDescentToken); ok {
+		return nil, fmt.Errorf("Recursive descent must not be the last token")
+	}
+
+	foundVals, err := FindAllOp{Path: op.Path}.Resolve(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	// Remove values starting from the end so that array indices stay valid
+	for i := len(foundVals) - 1; i >= 0; i-- {
+		doc, err = RemoveOp{Path: foundVals[i].Path}.Apply(doc)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return doc, nil
+}
@@ -40,6 +40,119 @@ var _ = Describe("RemoveOp.Apply", func() {
 				},
 			}))
 		})
+		It("removes map keys matched by a glob pattern", func() {
+			res, err := RemoveOp{Path: MustNewPointerFromString("/jobs/*_api")}.Apply(map[interface{}]interface{}{
+				"jobs": map[interface{}]interface{}{
+					"cloud_api": "job",
+					"uaa_api":   "job",
+					"cloud_db":  "job",
+				},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{
+				"jobs": map[interface{}]interface{}{"cloud_db": "job"},
+			}))
+		})
+
+		It("removes all map keys if wildcard is the last token just like a glob pattern", func() {
+			doc := func() interface{} {
+				return map[interface{}]interface{}{
+					"jobs": map[interface{}]interface{}{"api": "job", "db": "job"},
+				}
+			}
+
+			res, err := RemoveOp{Path: MustNewPointerFromString("/jobs/*")}.Apply(doc())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{"jobs": map[interface{}]interface{}{}}))
+
+			globRes, err := RemoveOp{Path: MustNewPointerFromString("/jobs/''*")}.Apply(doc())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(globRes).To(Equal(res))
+		})
+
+		It("removes map keys of any type matched by a wildcard", func() {
+			res, err := RemoveOp{Path: MustNewPointerFromString("/ports/*")}.Apply(map[interface{}]interface{}{
+				"ports": map[interface{}]interface{}{80: "a", 443: "b", "x": "c"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{"ports": map[interface{}]interface{}{}}))
+
+			res, err = RemoveOp{Path: MustNewPointerFromString("/**/password")}.Apply(map[interface{}]interface{}{
+				80: map[interface{}]interface{}{"password": "p", "user": "u"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{80: map[interface{}]interface{}{"user": "u"}}))
+		})
+
+		It("removes items from all maps matched by a map wildcard", func() {
+			res, err := RemoveOp{Path: MustNewPointerFromString("/properties/*/password?")}.Apply(map[interface{}]interface{}{
+				"properties": map[interface{}]interface{}{
+					"api":    map[interface{}]interface{}{"user": "u", "password": "p"},
+					"worker": map[interface{}]interface{}{"user": "u"},
+				},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{
+				"properties": map[interface{}]interface{}{
+					"api":    map[interface{}]interface{}{"user": "u"},
+					"worker": map[interface{}]interface{}{"user": "u"},
+				},
+			}))
+		})
+	})
+
+	Describe("recursive remove", func() {
+		It("removes values wherever they are found", func() {
+			res, err := RemoveOp{Path: MustNewPointerFromString("/**/password")}.Apply(map[interface{}]interface{}{
+				"password": "p",
+				"instance_groups": []interface{}{
+					map[interface{}]interface{}{
+						"name": "api",
+						"jobs": []interface{}{
+							map[interface{}]interface{}{"name": "capi", "password": "p"},
+							map[interface{}]interface{}{"name": "nats", "password": map[interface{}]interface{}{"password": "p"}},
+						},
+					},
+				},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{
+				"instance_groups": []interface{}{
+					map[interface{}]interface{}{
+						"name": "api",
+						"jobs": []interface{}{
+							map[interface{}]interface{}{"name": "capi"},
+							map[interface{}]interface{}{"name": "nats"},
+						},
+					},
+				},
+			}))
+		})
+
+		It("removes matched array items starting from the end", func() {
+			res, err := RemoveOp{Path: MustNewPointerFromString("/**/name=tmp")}.Apply(map[interface{}]interface{}{
+				"a": []interface{}{
+					map[interface{}]interface{}{"name": "tmp"},
+					map[interface{}]interface{}{"name": "keep", "b": []interface{}{map[interface{}]interface{}{"name": "tmp"}}},
+				},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{
+				"a": []interface{}{
+					map[interface{}]interface{}{"name": "keep", "b": []interface{}{}},
+				},
+			}))
+		})
+
+		It("returns an error if nothing matched unless recursive descent is optional", func() {
+			_, err := RemoveOp{Path: MustNewPointerFromString("/**/password")}.Apply(map[interface{}]interface{}{"a": 1})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected to find at least one value matching path '/**/password'"))
+
+			res, err := RemoveOp{Path: MustNewPointerFromString("/**?/password")}.Apply(map[interface{}]interface{}{"a": 1})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{"a": 1}))
+		})
 	})
 
 	It("returns an error if path is for the entire document", func() {
@@ -127,11 +240,71 @@ var _ = Describe("RemoveOp.Apply", func() {
 		})
 	})
 
+	Describe("array range", func() {
+		It("removes array items in range", func() {
+			doc := []interface{}{1, 2, 3, 4, 5}
+
+			res, err := RemoveOp{Path: MustNewPointerFromString("/1..3")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{1, 4, 5}))
+
+			res, err = RemoveOp{Path: MustNewPointerFromString("/..2")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{3, 4, 5}))
+
+			res, err = RemoveOp{Path: MustNewPointerFromString("/-2..")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{1, 2, 3}))
+
+			res, err = RemoveOp{Path: MustNewPointerFromString("/..")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{}))
+		})
+
+		It("removes array items in range from an array that is inside a map", func() {
+			doc := map[interface{}]interface{}{
+				"abc": []interface{}{1, 2, 3},
+			}
+
+			res, err := RemoveOp{Path: MustNewPointerFromString("/abc/..-1")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{
+				"abc": []interface{}{3},
+			}))
+		})
+
+		It("returns an error if range is out of bounds", func() {
+			_, err := RemoveOp{Path: MustNewPointerFromString("/-4..")}.Apply([]interface{}{1, 2, 3})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(
+				"Expected to find array index '-4' but found array of length '3' for path '/-4..'"))
+		})
+
+		It("returns an error if range token is not last", func() {
+			_, err := RemoveOp{Path: MustNewPointerFromString("/1..2/0")}.Apply([]interface{}{1, 2, 3})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected range token to be last in path '/1..2/0'"))
+		})
+	})
+
 	It("returns an error if after last token is found", func() {
 		_, err := RemoveOp{Path: MustNewPointerFromString("/-")}.Apply([]interface{}{})
 		Expect(err).To(HaveOccurred())
 		Expect(err.Error()).To(Equal(
 			"Expected to not find token 'patch.AfterLastIndexToken' at path '/-'"))
+
+		_, err = RemoveOp{Path: MustNewPointerFromString("/items/-/name")}.Apply(map[interface{}]interface{}{
+			"items": []interface{}{map[interface{}]interface{}{"name": "api"}},
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal(
+			"Expected to not find token 'patch.AfterLastIndexToken' at path '/items/-'"))
+
+		res, err := RemoveOp{Path: MustNewPointerFromString("/-/name")}.Apply(map[interface{}]interface{}{
+			"-": map[interface{}]interface{}{"name": "api"},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(map[interface{}]interface{}{"-": map[interface{}]interface{}{}}))
 	})
 
 	Describe("array item with matching key and value", func() {
@@ -195,6 +368,84 @@ var _ = Describe("RemoveOp.Apply", func() {
 				"Expected to find exactly one matching array item for path '/key=val' but found 2"))
 		})
 
+		It("removes selected array items if multiple items found", func() {
+			doc := func() []interface{} {
+				return []interface{}{
+					map[interface{}]interface{}{"key": "val", "idx": 0},
+					map[interface{}]interface{}{"key": "val2", "idx": 1},
+					map[interface{}]interface{}{"key": "val", "idx": 2, "nested": "nested"},
+				}
+			}
+
+			res, err := RemoveOp{Path: MustNewPointerFromString("/key=val:all")}.Apply(doc())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{
+				map[interface{}]interface{}{"key": "val2", "idx": 1},
+			}))
+
+			res, err = RemoveOp{Path: MustNewPointerFromString("/key=val:first")}.Apply(doc())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{
+				map[interface{}]interface{}{"key": "val2", "idx": 1},
+				map[interface{}]interface{}{"key": "val", "idx": 2, "nested": "nested"},
+			}))
+
+			res, err = RemoveOp{Path: MustNewPointerFromString("/key=val:all/nested?")}.Apply(doc())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{
+				map[interface{}]interface{}{"key": "val", "idx": 0},
+				map[interface{}]interface{}{"key": "val2", "idx": 1},
+				map[interface{}]interface{}{"key": "val", "idx": 2},
+			}))
+		})
+
+		It("removes array item matching all predicates", func() {
+			doc := []interface{}{
+				map[interface{}]interface{}{"name": "api", "az": "z1"},
+				map[interface{}]interface{}{"name": "api", "az": "z2"},
+			}
+
+			res, err := RemoveOp{Path: MustNewPointerFromString("/name=api,az=z1")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{
+				map[interface{}]interface{}{"name": "api", "az": "z2"},
+			}))
+		})
+
+		It("removes array items using predicate operators", func() {
+			doc := []interface{}{
+				map[interface{}]interface{}{"name": "errand-smoke"},
+				map[interface{}]interface{}{"name": "api"},
+				map[interface{}]interface{}{"name": "errand-acceptance"},
+			}
+
+			res, err := RemoveOp{Path: MustNewPointerFromString("/name^=errand-:all")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{
+				map[interface{}]interface{}{"name": "api"},
+			}))
+		})
+
+		It("removes scalar array items by value", func() {
+			doc := map[interface{}]interface{}{
+				"tags":  []interface{}{"a", "b", "a"},
+				"ports": []interface{}{80, 443},
+			}
+
+			res, err := RemoveOp{Path: MustNewPointerFromString("/tags/=a:all")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+
+			res, err = RemoveOp{Path: MustNewPointerFromString("/ports/=80")}.Apply(res)
+			Expect(err).ToNot(HaveOccurred())
+
+			res, err = RemoveOp{Path: MustNewPointerFromString("/ports/=8080?")}.Apply(res)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{
+				"tags":  []interface{}{"b"},
+				"ports": []interface{}{443},
+			}))
+		})
+
 		It("removes array item even if not all items are maps", func() {
 			doc := []interface{}{
 				3,
@@ -302,6 +553,18 @@ var _ = Describe("RemoveOp.Apply", func() {
 			Expect(res).To(Equal(map[interface{}]interface{}{"xyz": "xyz"}))
 		})
 
+		It("removes non-string map key by its tag", func() {
+			doc := map[interface{}]interface{}{80: "http", "80": "string", nil: "null"}
+
+			res, err := RemoveOp{Path: MustNewPointerFromString("/!!int 80")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{"80": "string", nil: "null"}))
+
+			res, err = RemoveOp{Path: MustNewPointerFromString("/!!null ~")}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{"80": "string"}))
+		})
+
 		It("removes nested map key", func() {
 			doc := map[interface{}]interface{}{
 				"abc": map[interface{}]interface{}{
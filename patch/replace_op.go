@@ -15,6 +15,7 @@ type mutationCtx struct {
 	PrevUpdate func(interface{})
 	I          int
 	Obj        interface{}
+	New        bool // Obj was just appended so that its nested values are created
 }
 
 func replaceOpCloneValueErr(err error) error {
@@ -30,9 +31,30 @@ func (op ReplaceOp) Apply(doc interface{}) (interface{}, error) {
 		if err != nil {
 			return nil, replaceOpCloneValueErr(err)
 		}
+		if valMapType, found := docMapType(op.Value); found {
+			clonedValue = valMapType.Convert(clonedValue)
+		}
 		return clonedValue, nil
 	}
 
+	if isRecursive(op.Path) {
+		return op.applyRecursive(doc)
+	}
+
+	// New values and intermediate maps use the same map type as the document
+	mapType, found := docMapType(doc)
+	if !found {
+		mapType, _ = docMapType(op.Value)
+	}
+
+	cloneValue := func() (interface{}, error) {
+		clonedValue, err := op.cloneValue(op.Value)
+		if err != nil {
+			return nil, replaceOpCloneValueErr(err)
+		}
+		return mapType.Convert(clonedValue), nil
+	}
+
 	ctxStack := []*mutationCtx{&mutationCtx{
 		PrevUpdate: func(newObj interface{}) { doc = newObj },
 		I:          0,
@@ -52,6 +74,8 @@ func (op ReplaceOp) Apply(doc interface{}) (interface{}, error) {
 		isLast := ctx.I == len(tokens)-2
 		currPath := NewPointer(tokens[:ctx.I+2])
 
+		token = afterLastIndexKey(token, ctx.Obj, isLast)
+
 		switch typedToken := token.(type) {
 		case IndexToken:
 			typedObj, ok := ctx.Obj.([]interface{})
@@ -60,9 +84,9 @@ func (op ReplaceOp) Apply(doc interface{}) (interface{}, error) {
 			}
 
 			if isLast {
-				clonedValue, err := op.cloneValue(op.Value)
+				clonedValue, err := cloneValue()
 				if err != nil {
-					return nil, replaceOpCloneValueErr(err)
+					return nil, err
 				}
 				idx, err := ArrayInsertion{Index: typedToken.Index, Modifiers: typedToken.Modifiers, Array: typedObj, Path: currPath}.Concrete()
 				if err != nil {
@@ -88,128 +112,204 @@ func (op ReplaceOp) Apply(doc interface{}) (interface{}, error) {
 			}
 
 			if isLast {
-				clonedValue, err := op.cloneValue(op.Value)
+				clonedValue, err := cloneValue()
 				if err != nil {
-					return nil, replaceOpCloneValueErr(err)
+					return nil, err
 				}
 				ctx.PrevUpdate(append(typedObj, clonedValue))
 			} else {
-				return nil, fmt.Errorf("Expected after last index token to be last in path '%s'", op.Path)
+				o, err := op.newContainer(tokens[ctx.I+2], mapType, NewPointer(tokens[:ctx.I+3]))
+				if err != nil {
+					return nil, err
+				}
+
+				newAry := append(typedObj, o)
+				idx := len(newAry) - 1
+
+				ctx.PrevUpdate(newAry)
+				ctxStack = append(ctxStack, &mutationCtx{
+					PrevUpdate: func(newObj interface{}) { newAry[idx] = newObj },
+					I:          ctx.I + 1,
+					Obj:        o,
+					New:        true,
+				})
+			}
+
+		case RangeToken:
+			typedObj, ok := ctx.Obj.([]interface{})
+			if !ok {
+				return nil, NewOpArrayMismatchTypeErr(currPath, ctx.Obj)
+			}
+
+			if !isLast {
+				return nil, fmt.Errorf("Expected range token to be last in path '%s'", op.Path)
+			}
+
+			start, end, err := ArrayRange{Start: typedToken.Start, End: typedToken.End, ToEnd: typedToken.ToEnd, Array: typedObj, Path: currPath}.Concrete()
+			if err != nil {
+				return nil, err
+			}
+
+			clonedValue, err := cloneValue()
+			if err != nil {
+				return nil, err
+			}
+
+			vals, ok := clonedValue.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("Expected value to be an array to replace range at path '%s' but found '%T'", currPath, op.Value)
 			}
 
+			newAry := append([]interface{}{}, typedObj[:start]...)
+			newAry = append(newAry, vals...)
+			newAry = append(newAry, typedObj[end:]...)
+
+			ctx.PrevUpdate(newAry)
+
 		case MatchingIndexToken:
 			typedObj, ok := ctx.Obj.([]interface{})
 			if !ok {
 				return nil, NewOpArrayMismatchTypeErr(currPath, ctx.Obj)
 			}
 
-			var idxs []int
+			idxs := matchingIndexes(typedToken, typedObj)
 
-			for itemIdx, item := range typedObj {
-				typedItem, ok := item.(map[interface{}]interface{})
-				if ok {
-					if typedItem[typedToken.Key] == typedToken.Value {
-						idxs = append(idxs, itemIdx)
-					}
+			if (typedToken.Optional || ctx.New) && len(idxs) == 0 {
+				pos, err := missingMatchingPosition(typedToken, typedObj, currPath)
+				if err != nil {
+					return nil, err
 				}
-			}
 
-			if typedToken.Optional && len(idxs) == 0 {
+				insertion := ArrayInsertionIndex{number: pos, insert: true}
+
 				if isLast {
-					clonedValue, err := op.cloneValue(op.Value)
+					clonedValue, err := cloneValue()
 					if err != nil {
-						return nil, replaceOpCloneValueErr(err)
+						return nil, err
 					}
-					ctx.PrevUpdate(append(typedObj, clonedValue))
+					ctx.PrevUpdate(insertion.Update(typedObj, clonedValue))
 				} else {
-					o := map[interface{}]interface{}{typedToken.Key: typedToken.Value}
-					ctx.PrevUpdate(append(typedObj, o))
+					o, err := newMatchingItem(mapType, typedToken, currPath)
+					if err != nil {
+						return nil, err
+					}
+
+					ctx.PrevUpdate(insertion.Update(typedObj, o))
 					ctxStack = append(ctxStack, &mutationCtx{
 						PrevUpdate: ctx.PrevUpdate, // no need to change prevUpdate since matching item can only be a map
 						I:          ctx.I + 1,
 						Obj:        o,
+						New:        ctx.New,
 					})
 				}
 			} else {
-				if len(idxs) != 1 {
-					return nil, OpMultipleMatchingIndexErr{currPath, idxs}
+				idxs, modifiers, err := selectMatchingIndexes(typedToken, idxs, currPath)
+				if err != nil {
+					return nil, err
 				}
 
 				if isLast {
-					clonedValue, err := op.cloneValue(op.Value)
-					idx, err := ArrayInsertion{Index: idxs[0], Modifiers: typedToken.Modifiers, Array: typedObj, Path: currPath}.Concrete()
-					if err != nil {
-						return nil, err
+					newAry := typedObj
+
+					// Update items starting from the end so that insertions do not shift indices
+					for i := len(idxs) - 1; i >= 0; i-- {
+						clonedValue, err := cloneValue()
+						if err != nil {
+							return nil, err
+						}
+						idx, err := ArrayInsertion{Index: idxs[i], Modifiers: modifiers, Array: typedObj, Path: currPath}.Concrete()
+						if err != nil {
+							return nil, err
+						}
+
+						newAry = idx.Update(newAry, clonedValue)
 					}
 
-					ctx.PrevUpdate(idx.Update(typedObj, clonedValue))
+					ctx.PrevUpdate(newAry)
 				} else {
-					idx, err := ArrayIndex{Index: idxs[0], Modifiers: typedToken.Modifiers, Array: typedObj, Path: currPath}.Concrete()
-					if err != nil {
-						return nil, err
+					for _, idx := range idxs {
+						idx, err := ArrayIndex{Index: idx, Modifiers: modifiers, Array: typedObj, Path: currPath}.Concrete()
+						if err != nil {
+							return nil, err
+						}
+
+						ctxStack = append(ctxStack, &mutationCtx{
+							PrevUpdate: func(newObj interface{}) { typedObj[idx] = newObj },
+							I:          ctx.I + 1,
+							Obj:        typedObj[idx],
+						})
 					}
-
-					// no need to change prevUpdate since matching item can only be a map
-					ctxStack = append(ctxStack, &mutationCtx{
-						PrevUpdate: ctx.PrevUpdate, // no need to change prevUpdate since matching item can only be a map
-						I:          ctx.I + 1,
-						Obj:        typedObj[idx],
-					})
 				}
 			}
 
 		case KeyToken:
-			typedObj, ok := ctx.Obj.(map[interface{}]interface{})
+			typedObj, ok := newGenericMap(ctx.Obj)
 			if !ok {
 				return nil, NewOpMapMismatchTypeErr(currPath, ctx.Obj)
 			}
 
-			o, found := typedObj[typedToken.Key]
-			if !found && !typedToken.Optional {
-				return nil, OpMissingMapKeyErr{typedToken.Key, currPath, typedObj}
+			o, found := typedObj.Get(typedToken.mapKey())
+			if !found && !typedToken.Optional && !ctx.New {
+				return nil, newOpMissingMapKeyErr(typedToken.Key, currPath, typedObj)
 			}
 
 			if isLast {
-				clonedValue, err := op.cloneValue(op.Value)
+				clonedValue, err := cloneValue()
 				if err != nil {
-					return nil, replaceOpCloneValueErr(err)
+					return nil, err
 				}
-				typedObj[typedToken.Key] = clonedValue
+				typedObj.Set(typedToken.mapKey(), clonedValue)
 			} else {
 				if !found {
-					// Determine what type of value to create based on next token
-					switch tokens[ctx.I+2].(type) {
-					case AfterLastIndexToken:
-						o = []interface{}{}
-					case WildcardToken:
-						o = []interface{}{}
-					case MatchingIndexToken:
-						o = []interface{}{}
-					case KeyToken:
-						o = map[interface{}]interface{}{}
-					default:
-						errMsg := "Expected to find key, matching index or after last index token at path '%s'"
-						return nil, fmt.Errorf(errMsg, NewPointer(tokens[:ctx.I+3]))
+					var err error
+
+					o, err = op.newContainer(tokens[ctx.I+2], typedObj.Type(), NewPointer(tokens[:ctx.I+3]))
+					if err != nil {
+						return nil, err
 					}
 
-					typedObj[typedToken.Key] = o
+					typedObj.Set(typedToken.mapKey(), o)
 				}
 
 				ctxStack = append(ctxStack, &mutationCtx{
-					PrevUpdate: func(newObj interface{}) { typedObj[typedToken.Key] = newObj },
+					PrevUpdate: func(newObj interface{}) { typedObj.Set(typedToken.mapKey(), newObj) },
 					I:          ctx.I + 1,
 					Obj:        o,
+					New:        ctx.New,
 				})
 			}
 
 		case WildcardToken:
+			// Wildcard over map keys behaves the same as '*' glob pattern
+			if typedObj, ok := newGenericMap(ctx.Obj); ok {
+				for _, key := range sortedKeys(typedObj) {
+					key := key
+
+					if isLast {
+						clonedValue, err := cloneValue()
+						if err != nil {
+							return nil, err
+						}
+						typedObj.Set(key, clonedValue)
+					} else {
+						o, _ := typedObj.Get(key)
+						ctxStack = append(ctxStack, &mutationCtx{
+							PrevUpdate: func(newObj interface{}) { typedObj.Set(key, newObj) },
+							I:          ctx.I + 1,
+							Obj:        o,
+						})
+					}
+				}
+				break
+			}
+
 			if isLast {
 				return nil, fmt.Errorf("Wildcard must not be the last token")
 			}
 
 			typedObj, ok := ctx.Obj.([]interface{})
 			if !ok {
-				return nil, NewOpArrayMismatchTypeErr(currPath, ctx.Obj)
+				return nil, OpMismatchTypeErr{"a map or an array", currPath, ctx.Obj}
 			}
 
 			for idx, o := range typedObj {
@@ -220,6 +320,31 @@ func (op ReplaceOp) Apply(doc interface{}) (interface{}, error) {
 				})
 			}
 
+		case KeyGlobToken:
+			typedObj, ok := newGenericMap(ctx.Obj)
+			if !ok {
+				return nil, NewOpMapMismatchTypeErr(currPath, ctx.Obj)
+			}
+
+			for _, key := range matchingKeys(typedToken, typedObj) {
+				key := key
+
+				if isLast {
+					clonedValue, err := cloneValue()
+					if err != nil {
+						return nil, err
+					}
+					typedObj.Set(key, clonedValue)
+				} else {
+					o, _ := typedObj.Get(key)
+					ctxStack = append(ctxStack, &mutationCtx{
+						PrevUpdate: func(newObj interface{}) { typedObj.Set(key, newObj) },
+						I:          ctx.I + 1,
+						Obj:        o,
+					})
+				}
+			}
+
 		default:
 			return nil, OpUnexpectedTokenErr{token, currPath}
 		}
@@ -228,7 +353,26 @@ func (op ReplaceOp) Apply(doc interface{}) (interface{}, error) {
 	return doc, nil
 }
 
+// newContainer returns an empty value of the type that next token could be applied to
+func (ReplaceOp) newContainer(nextToken Token, mapType mapType, nextPath Pointer) (interface{}, error) {
+	switch nextToken.(type) {
+	case AfterLastIndexToken, WildcardToken, MatchingIndexToken:
+		return []interface{}{}, nil
+	case KeyToken, KeyGlobToken:
+		return mapType.New().Obj(), nil
+	default:
+		errMsg := "Expected to find key, matching index or after last index token at path '%s'"
+		return nil, fmt.Errorf(errMsg, nextPath)
+	}
+}
+
 func (ReplaceOp) cloneValue(in interface{}) (out interface{}, err error) {
+	// Avoid YAML round trip for values that are already made of
+	// basic types so that numbers (e.g. float64 from encoding/json) keep their type
+	if isBasicValue(in) {
+		return copyValue(in), nil
+	}
+
 	defer func() {
 		if recoverVal := recover(); recoverVal != nil {
 			err = fmt.Errorf("Recovered: %s", recoverVal)
@@ -247,3 +391,79 @@ func (ReplaceOp) cloneValue(in interface{}) (out interface{}, err error) {
 
 	return out, nil
 }
+
+// applyRecursive replaces values at every location matched by the path.
+// Values are only added when the last token is optional or after last index token.
+func (op ReplaceOp) applyRecursive(doc interface{}) (interface{}, error) {
+	tokens := op.Path.Tokens()
+	lastToken := tokens[len(tokens)-1]
+
+	if _, ok := lastToken.(RecursiveDescentToken); ok {
+		return nil, fmt.Errorf("Recursive descent must not be the last token")
+	}
+
+	var paths []Pointer
+
+	if isCreatingToken(lastToken) {
+		parents, err := FindAllOp{Path: NewPointer(tokens[:len(tokens)-1])}.resolve(doc)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, parent := range parents {
+			if canHoldToken(parent.Value, lastToken) {
+				paths = append(paths, NewPointer(append(parent.Path.Tokens(), lastToken)))
+			}
+		}
+
+		if len(paths) == 0 && !isOptionalRecursive(op.Path) {
+			return nil, fmt.Errorf("Expected to find at least one value matching path '%s'", op.Path)
+		}
+	} else {
+		foundVals, err := FindAllOp{Path: op.Path}.Resolve(doc)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, foundVal := range foundVals {
+			paths = append(paths, foundVal.Path)
+		}
+	}
+
+	// Replace nested values before values that contain them
+	for i := len(paths) - 1; i >= 0; i-- {
+		var err error
+
+		doc, err = ReplaceOp{Path: paths[i], Value: op.Value}.Apply(doc)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return doc, nil
+}
+
+// isCreatingToken checks if last token may add a new value
+func isCreatingToken(token Token) bool {
+	switch typedToken := token.(type) {
+	case AfterLastIndexToken:
+		return true
+	case KeyToken:
+		return typedToken.Optional
+	case MatchingIndexToken:
+		return typedToken.Optional
+	}
+	return false
+}
+
+// canHoldToken checks if value is a collection that token may be applied to
+func canHoldToken(obj interface{}, token Token) bool {
+	switch token.(type) {
+	case KeyToken:
+		_, ok := newGenericMap(obj)
+		return ok
+	default:
+		_, ok := obj.([]interface{})
+		return ok
+	}
+}
@@ -35,6 +35,192 @@ var _ = Describe("ReplaceOp.Apply", func() {
 				},
 			}))
 		})
+		It("replaces values in all maps matched by a map wildcard", func() {
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/properties/*/tls?/enabled"), Value: true}.Apply(map[interface{}]interface{}{
+				"properties": map[interface{}]interface{}{
+					"api":    map[interface{}]interface{}{"tls": map[interface{}]interface{}{"enabled": false}},
+					"worker": map[interface{}]interface{}{},
+				},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{
+				"properties": map[interface{}]interface{}{
+					"api":    map[interface{}]interface{}{"tls": map[interface{}]interface{}{"enabled": true}},
+					"worker": map[interface{}]interface{}{"tls": map[interface{}]interface{}{"enabled": true}},
+				},
+			}))
+		})
+
+		It("replaces values of map keys matched by a glob pattern", func() {
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/jobs/*_api"), Value: "new"}.Apply(map[interface{}]interface{}{
+				"jobs": map[interface{}]interface{}{
+					"cloud_api":  "old",
+					"uaa_api":    "old",
+					"cloud_db":   "old",
+					"api_worker": "old",
+				},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{
+				"jobs": map[interface{}]interface{}{
+					"cloud_api":  "new",
+					"uaa_api":    "new",
+					"cloud_db":   "old",
+					"api_worker": "old",
+				},
+			}))
+		})
+
+		It("replaces all map values if wildcard is the last token just like a glob pattern", func() {
+			doc := func() interface{} {
+				return map[interface{}]interface{}{
+					"jobs": map[interface{}]interface{}{"api": "old", "db": "old"},
+				}
+			}
+
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/jobs/*"), Value: "new"}.Apply(doc())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{
+				"jobs": map[interface{}]interface{}{"api": "new", "db": "new"},
+			}))
+
+			globRes, err := ReplaceOp{Path: MustNewPointerFromString("/jobs/''*"), Value: "new"}.Apply(doc())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(globRes).To(Equal(res))
+
+			_, err = ReplaceOp{Path: MustNewPointerFromString("/*"), Value: "new"}.Apply([]interface{}{1})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Wildcard must not be the last token"))
+		})
+
+		It("replaces map values under keys of any type matched by a wildcard", func() {
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/ports/*"), Value: "new"}.Apply(map[interface{}]interface{}{
+				"ports": map[interface{}]interface{}{80: "a", 443: "b", "x": "c"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{
+				"ports": map[interface{}]interface{}{80: "new", 443: "new", "x": "new"},
+			}))
+
+			res, err = ReplaceOp{Path: MustNewPointerFromString("/ports/*/name?"), Value: "new"}.Apply(map[interface{}]interface{}{
+				"ports": map[interface{}]interface{}{80: map[interface{}]interface{}{}},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{
+				"ports": map[interface{}]interface{}{80: map[interface{}]interface{}{"name": "new"}},
+			}))
+		})
+
+		It("does not change anything if glob pattern does not match any keys", func() {
+			doc := map[interface{}]interface{}{"jobs": map[interface{}]interface{}{"db": "old"}}
+
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/jobs/*_api/name"), Value: "new"}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{"jobs": map[interface{}]interface{}{"db": "old"}}))
+		})
+
+		It("returns an error if glob pattern is used on a non-map", func() {
+			_, err := ReplaceOp{Path: MustNewPointerFromString("/jobs/*_api"), Value: "new"}.Apply(map[interface{}]interface{}{
+				"jobs": []interface{}{"cloud_api"},
+			})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected to find a map at path '/jobs/*_api' but found '[]interface {}'"))
+		})
+	})
+
+	Describe("recursive replace", func() {
+		doc := func() interface{} {
+			return map[interface{}]interface{}{
+				"instance_groups": []interface{}{
+					map[interface{}]interface{}{
+						"name": "api",
+						"properties": map[interface{}]interface{}{
+							"tls": map[interface{}]interface{}{"ca": "old"},
+						},
+						"jobs": []interface{}{
+							map[interface{}]interface{}{
+								"name": "capi",
+								"tls":  map[interface{}]interface{}{"ca": "old", "cert": "cert"},
+							},
+						},
+					},
+					map[interface{}]interface{}{
+						"name": "worker",
+						"tls":  map[interface{}]interface{}{},
+					},
+				},
+			}
+		}
+
+		It("replaces values wherever they are found", func() {
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/instance_groups/**/tls/ca"), Value: "new"}.Apply(doc())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{
+				"instance_groups": []interface{}{
+					map[interface{}]interface{}{
+						"name": "api",
+						"properties": map[interface{}]interface{}{
+							"tls": map[interface{}]interface{}{"ca": "new"},
+						},
+						"jobs": []interface{}{
+							map[interface{}]interface{}{
+								"name": "capi",
+								"tls":  map[interface{}]interface{}{"ca": "new", "cert": "cert"},
+							},
+						},
+					},
+					map[interface{}]interface{}{
+						"name": "worker",
+						"tls":  map[interface{}]interface{}{},
+					},
+				},
+			}))
+		})
+
+		It("adds values if last token is optional", func() {
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/instance_groups/**/tls/ca?"), Value: "new"}.Apply(doc())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{
+				"instance_groups": []interface{}{
+					map[interface{}]interface{}{
+						"name": "api",
+						"properties": map[interface{}]interface{}{
+							"tls": map[interface{}]interface{}{"ca": "new"},
+						},
+						"jobs": []interface{}{
+							map[interface{}]interface{}{
+								"name": "capi",
+								"tls":  map[interface{}]interface{}{"ca": "new", "cert": "cert"},
+							},
+						},
+					},
+					map[interface{}]interface{}{
+						"name": "worker",
+						"tls":  map[interface{}]interface{}{"ca": "new"},
+					},
+				},
+			}))
+		})
+
+		It("returns an error if nothing matched unless recursive descent is optional", func() {
+			_, err := ReplaceOp{Path: MustNewPointerFromString("/**/password"), Value: "new"}.Apply(doc())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected to find at least one value matching path '/**/password'"))
+
+			_, err = ReplaceOp{Path: MustNewPointerFromString("/**/vms/-"), Value: "new"}.Apply(doc())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected to find at least one value matching path '/**/vms/-'"))
+
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/**?/password"), Value: "new"}.Apply(doc())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(doc()))
+		})
+
+		It("returns an error if recursive descent is the last token", func() {
+			_, err := ReplaceOp{Path: MustNewPointerFromString("/instance_groups/**"), Value: "new"}.Apply(doc())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Recursive descent must not be the last token"))
+		})
 	})
 
 	It("returns error if replacement value cloning fails", func() {
@@ -147,6 +333,56 @@ var _ = Describe("ReplaceOp.Apply", func() {
 		})
 	})
 
+	Describe("array range", func() {
+		It("replaces array items in range with given values", func() {
+			doc := []interface{}{1, 2, 3, 4, 5}
+
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/1..3"), Value: []interface{}{10, 11, 12}}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{1, 10, 11, 12, 4, 5}))
+
+			res, err = ReplaceOp{Path: MustNewPointerFromString("/-2.."), Value: []interface{}{}}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{1, 2, 3}))
+
+			res, err = ReplaceOp{Path: MustNewPointerFromString("/..0"), Value: []interface{}{0}}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{0, 1, 2, 3, 4, 5}))
+		})
+
+		It("replaces array items in range from an array that is inside a map", func() {
+			doc := map[interface{}]interface{}{
+				"abc": []interface{}{1, 2, 3},
+			}
+
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/abc/1.."), Value: []interface{}{"x"}}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{
+				"abc": []interface{}{1, "x"},
+			}))
+		})
+
+		It("returns an error if value is not an array", func() {
+			_, err := ReplaceOp{Path: MustNewPointerFromString("/1..2"), Value: 10}.Apply([]interface{}{1, 2, 3})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(
+				"Expected value to be an array to replace range at path '/1..2' but found 'int'"))
+		})
+
+		It("returns an error if range is out of bounds", func() {
+			_, err := ReplaceOp{Path: MustNewPointerFromString("/2..5"), Value: []interface{}{}}.Apply([]interface{}{1, 2, 3})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(
+				"Expected to find array index '5' but found array of length '3' for path '/2..5'"))
+		})
+
+		It("returns an error if range token is not last", func() {
+			_, err := ReplaceOp{Path: MustNewPointerFromString("/1..2/0"), Value: 1}.Apply([]interface{}{1, 2, 3})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected range token to be last in path '/1..2/0'"))
+		})
+	})
+
 	Describe("array with after last item", func() {
 		It("appends new item", func() {
 			res, err := ReplaceOp{Path: MustNewPointerFromString("/-"), Value: 10}.Apply([]interface{}{})
@@ -179,13 +415,103 @@ var _ = Describe("ReplaceOp.Apply", func() {
 			}))
 		})
 
-		It("returns an error if after last index token is not last", func() {
-			ptr := NewPointer([]Token{RootToken{}, AfterLastIndexToken{}, KeyToken{}})
+		It("appends new map and sets its key if after last index token is not last", func() {
+			doc := map[interface{}]interface{}{
+				"instance_groups": []interface{}{
+					map[interface{}]interface{}{"name": "api"},
+				},
+			}
+
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/instance_groups/-/name"), Value: "worker"}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{
+				"instance_groups": []interface{}{
+					map[interface{}]interface{}{"name": "api"},
+					map[interface{}]interface{}{"name": "worker"},
+				},
+			}))
+
+			res, err = ReplaceOp{Path: MustNewPointerFromString("/instance_groups/-1/jobs?/-/properties/port"), Value: 8080}.Apply(res)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{
+				"instance_groups": []interface{}{
+					map[interface{}]interface{}{"name": "api"},
+					map[interface{}]interface{}{
+						"name": "worker",
+						"jobs": []interface{}{
+							map[interface{}]interface{}{
+								"properties": map[interface{}]interface{}{"port": 8080},
+							},
+						},
+					},
+				},
+			}))
+		})
+
+		It("appends nested arrays and matching items if after last index token is not last", func() {
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/-/-"), Value: 1}.Apply([]interface{}{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{[]interface{}{1}}))
+
+			res, err = ReplaceOp{Path: MustNewPointerFromString("/-/jobs/name=capi/release"), Value: "capi"}.Apply([]interface{}{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{
+				map[interface{}]interface{}{
+					"jobs": []interface{}{
+						map[interface{}]interface{}{"name": "capi", "release": "capi"},
+					},
+				},
+			}))
+		})
+
+		It("treats '-' in the middle of a path as map key in maps", func() {
+			doc := map[interface{}]interface{}{
+				"-": map[interface{}]interface{}{"key": "val"},
+			}
+
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/-/key"), Value: "val2"}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{
+				"-": map[interface{}]interface{}{"key": "val2"},
+			}))
+		})
+
+		It("treats quoted '-' as map key even if it's applied to an array", func() {
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/m?/'-'/x"), Value: 1}.Apply(map[interface{}]interface{}{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{
+				"m": map[interface{}]interface{}{
+					"-": map[interface{}]interface{}{"x": 1},
+				},
+			}))
 
-			_, err := ReplaceOp{Path: ptr}.Apply([]interface{}{})
+			_, err = ReplaceOp{Path: MustNewPointerFromString("/items/'-'/name"), Value: 1}.Apply(map[interface{}]interface{}{
+				"items": []interface{}{},
+			})
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(Equal(
-				"Expected after last index token to be last in path '/-/'"))
+				"Expected to find a map at path '/items/'-'' but found '[]interface {}'"))
+		})
+
+		It("prints after last index token in the middle of a path unquoted", func() {
+			ptr := NewPointer([]Token{RootToken{}, KeyToken{Key: "items"}, AfterLastIndexToken{}, KeyToken{Key: "name"}})
+			Expect(ptr.String()).To(Equal("/items/-/name"))
+			Expect(MustNewPointerFromString(ptr.String())).To(Equal(ptr))
+
+			res, err := ReplaceOp{Path: MustNewPointerFromString(ptr.String()), Value: "api"}.Apply(map[interface{}]interface{}{
+				"items": []interface{}{},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{
+				"items": []interface{}{map[interface{}]interface{}{"name": "api"}},
+			}))
+		})
+
+		It("returns an error if after last index token is followed by index token", func() {
+			_, err := ReplaceOp{Path: MustNewPointerFromString("/-/0"), Value: 1}.Apply([]interface{}{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(
+				"Expected to find key, matching index or after last index token at path '/-/0'"))
 		})
 
 		It("returns an error if it's not an array being accessed", func() {
@@ -282,6 +608,241 @@ var _ = Describe("ReplaceOp.Apply", func() {
 				"Expected to find exactly one matching array item for path '/key=val' but found 2"))
 		})
 
+		It("replaces selected array items if multiple items found", func() {
+			doc := func() []interface{} {
+				return []interface{}{
+					map[interface{}]interface{}{"key": "val", "idx": 0},
+					map[interface{}]interface{}{"key": "val2", "idx": 1},
+					map[interface{}]interface{}{"key": "val", "idx": 2},
+				}
+			}
+
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/key=val:all/idx"), Value: 10}.Apply(doc())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{
+				map[interface{}]interface{}{"key": "val", "idx": 10},
+				map[interface{}]interface{}{"key": "val2", "idx": 1},
+				map[interface{}]interface{}{"key": "val", "idx": 10},
+			}))
+
+			res, err = ReplaceOp{Path: MustNewPointerFromString("/key=val:last"), Value: 10}.Apply(doc())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{
+				map[interface{}]interface{}{"key": "val", "idx": 0},
+				map[interface{}]interface{}{"key": "val2", "idx": 1},
+				10,
+			}))
+		})
+
+		It("inserts items relative to selected array items", func() {
+			doc := func() []interface{} {
+				return []interface{}{
+					map[interface{}]interface{}{"key": "val", "idx": 0},
+					map[interface{}]interface{}{"key": "val2", "idx": 1},
+					map[interface{}]interface{}{"key": "val", "idx": 2},
+				}
+			}
+
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/key=val:all:after"), Value: 10}.Apply(doc())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{
+				map[interface{}]interface{}{"key": "val", "idx": 0},
+				10,
+				map[interface{}]interface{}{"key": "val2", "idx": 1},
+				map[interface{}]interface{}{"key": "val", "idx": 2},
+				10,
+			}))
+
+			res, err = ReplaceOp{Path: MustNewPointerFromString("/key=val:nth=1:before"), Value: 10}.Apply(doc())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{
+				map[interface{}]interface{}{"key": "val", "idx": 0},
+				map[interface{}]interface{}{"key": "val2", "idx": 1},
+				10,
+				map[interface{}]interface{}{"key": "val", "idx": 2},
+			}))
+		})
+
+		It("appends missing matching item if nothing is selected and matching is optional", func() {
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/key=val?:all/idx"), Value: 10}.Apply([]interface{}{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{
+				map[interface{}]interface{}{"key": "val", "idx": 10},
+			}))
+		})
+
+		It("adds missing optional matching item next to the item matching anchor modifier", func() {
+			doc := []interface{}{
+				map[interface{}]interface{}{"name": "bpm"},
+				map[interface{}]interface{}{"name": "bar"},
+			}
+
+			ptr := MustNewPointerFromString("/name=foo?:before=name=bar")
+			val := map[interface{}]interface{}{"name": "foo", "release": "foo"}
+
+			res, err := ReplaceOp{Path: ptr, Value: val}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{
+				map[interface{}]interface{}{"name": "bpm"},
+				map[interface{}]interface{}{"name": "foo", "release": "foo"},
+				map[interface{}]interface{}{"name": "bar"},
+			}))
+
+			// found item is replaced in place
+			res, err = ReplaceOp{Path: ptr, Value: val}.Apply(res)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{
+				map[interface{}]interface{}{"name": "bpm"},
+				map[interface{}]interface{}{"name": "foo", "release": "foo"},
+				map[interface{}]interface{}{"name": "bar"},
+			}))
+
+			res, err = ReplaceOp{Path: MustNewPointerFromString("/name=foo?:after=name=bpm/release"), Value: "foo"}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{
+				map[interface{}]interface{}{"name": "bpm"},
+				map[interface{}]interface{}{"name": "foo", "release": "foo"},
+				map[interface{}]interface{}{"name": "bar"},
+			}))
+		})
+
+		It("appends missing optional matching item if nothing matches anchor modifier", func() {
+			doc := []interface{}{map[interface{}]interface{}{"name": "bpm"}}
+
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/name=foo?:before=name=bar/release"), Value: "foo"}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{
+				map[interface{}]interface{}{"name": "bpm"},
+				map[interface{}]interface{}{"name": "foo", "release": "foo"},
+			}))
+		})
+
+		It("returns an error if multiple items match anchor modifier", func() {
+			doc := []interface{}{
+				map[interface{}]interface{}{"name": "bar"},
+				map[interface{}]interface{}{"name": "bar"},
+			}
+
+			_, err := ReplaceOp{Path: MustNewPointerFromString("/name=foo?:after=name=bar"), Value: 1}.Apply(doc)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(
+				"Expected to find exactly one matching array item for path '/name=foo?:after=name=bar' but found 2"))
+		})
+
+		It("adds missing optional matching item at the beginning or the end for insertion modifiers", func() {
+			doc := []interface{}{map[interface{}]interface{}{"name": "bar"}}
+
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/name=foo?:before"), Value: 1}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{1, map[interface{}]interface{}{"name": "bar"}}))
+
+			res, err = ReplaceOp{Path: MustNewPointerFromString("/name=foo?:after"), Value: 1}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{map[interface{}]interface{}{"name": "bar"}, 1}))
+		})
+
+		It("replaces array item matching all nested predicates", func() {
+			doc := []interface{}{
+				map[interface{}]interface{}{"name": "capi", "release": map[interface{}]interface{}{"name": "capi"}},
+				map[interface{}]interface{}{"name": "capi", "release": map[interface{}]interface{}{"name": "other"}},
+			}
+
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/name=capi,release.name=other/name"), Value: "new"}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{
+				map[interface{}]interface{}{"name": "capi", "release": map[interface{}]interface{}{"name": "capi"}},
+				map[interface{}]interface{}{"name": "new", "release": map[interface{}]interface{}{"name": "other"}},
+			}))
+		})
+
+		It("appends missing item with all predicates if it does not exist", func() {
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/name=capi,release.name=capi,release.version=1?/jobs"), Value: 2}.Apply([]interface{}{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{
+				map[interface{}]interface{}{
+					"name":    "capi",
+					"release": map[interface{}]interface{}{"name": "capi", "version": "1"},
+					"jobs":    2,
+				},
+			}))
+		})
+
+		It("appends missing item with string values unless values are tagged", func() {
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/name=on,version=1.10,az=,instances=!!int 0,enabled=!!bool true,weight=!!float 1.0?/jobs"), Value: 2}.Apply([]interface{}{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{
+				map[interface{}]interface{}{
+					"name":      "on",
+					"version":   "1.10",
+					"az":        "",
+					"instances": 0,
+					"enabled":   true,
+					"weight":    1.0,
+					"jobs":      2,
+				},
+			}))
+		})
+
+		It("replaces array items using predicate operators", func() {
+			doc := []interface{}{
+				map[interface{}]interface{}{"name": "api", "instances": 0},
+				map[interface{}]interface{}{"name": "worker", "instances": 2},
+				map[interface{}]interface{}{"name": "db", "instances": 1},
+			}
+
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/instances>0:all/instances"), Value: 3}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{
+				map[interface{}]interface{}{"name": "api", "instances": 0},
+				map[interface{}]interface{}{"name": "worker", "instances": 3},
+				map[interface{}]interface{}{"name": "db", "instances": 3},
+			}))
+
+			_, err = ReplaceOp{Path: MustNewPointerFromString("/instances>0/instances"), Value: 3}.Apply(doc)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(
+				"Expected to find exactly one matching array item for path '/instances>0' but found 2"))
+		})
+
+		It("appends missing item with values of equality predicates", func() {
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/name=api,instances>0,env=*?/env"), Value: "env"}.Apply([]interface{}{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{
+				map[interface{}]interface{}{"name": "api", "env": "env"},
+			}))
+		})
+
+		It("returns an error if missing item cannot be created without equality predicates", func() {
+			_, err := ReplaceOp{Path: MustNewPointerFromString("/name^=api?/env"), Value: "env"}.Apply([]interface{}{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(
+				"Expected to find at least one equality predicate to create missing array item for path '/name^=api?'"))
+
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/name^=api?"), Value: "api-1"}.Apply([]interface{}{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{"api-1"}))
+		})
+
+		It("replaces or appends scalar array item by value", func() {
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/azs/=z2?"), Value: "z2"}.Apply(map[interface{}]interface{}{
+				"azs": []interface{}{"z1"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{"azs": []interface{}{"z1", "z2"}}))
+
+			res, err = ReplaceOp{Path: MustNewPointerFromString("/azs/=z2?"), Value: "z2"}.Apply(res)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{"azs": []interface{}{"z1", "z2"}}))
+
+			res, err = ReplaceOp{Path: MustNewPointerFromString("/azs/=z1"), Value: "z3"}.Apply(res)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{"azs": []interface{}{"z3", "z2"}}))
+
+			res, err = ReplaceOp{Path: MustNewPointerFromString("/azs/=z2:before"), Value: "z0"}.Apply(res)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{"azs": []interface{}{"z3", "z0", "z2"}}))
+		})
+
 		It("replaces array item even if not all items are maps", func() {
 			doc := []interface{}{
 				3,
@@ -379,6 +940,22 @@ var _ = Describe("ReplaceOp.Apply", func() {
 			Expect(res).To(Equal(map[interface{}]interface{}{"abc": nil, "xyz": "xyz"}))
 		})
 
+		It("replaces non-string map key by its tag", func() {
+			doc := map[interface{}]interface{}{80: "http", "80": "string"}
+
+			res, err := ReplaceOp{Path: MustNewPointerFromString("/!!int 80"), Value: "https"}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{80: "https", "80": "string"}))
+
+			res, err = ReplaceOp{Path: MustNewPointerFromString("/!!bool true?/!!int 1"), Value: "x"}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{
+				80:   "https",
+				"80": "string",
+				true: map[interface{}]interface{}{1: "x"},
+			}))
+		})
+
 		It("replaces nested map key", func() {
 			doc := map[interface{}]interface{}{
 				"abc": map[interface{}]interface{}{
@@ -0,0 +1,374 @@
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	rfc6902Decoder = strings.NewReplacer("~1", "/", "~0", "~")
+)
+
+// RFC6902Definition struct is useful for JSON unmarshaling of RFC 6902 documents
+// (https://tools.ietf.org/html/rfc6902)
+type RFC6902Definition struct {
+	Op    string       `json:"op"`
+	Path  *string      `json:"path,omitempty"`
+	From  *string      `json:"from,omitempty"`
+	Value *interface{} `json:"value,omitempty"`
+}
+
+// UnmarshalJSON differs from default JSON unmarshaling by keeping
+// explicit null values and by decoding values the same way YAML library does
+// (maps with interface{} keys; integers as ints)
+func (d *RFC6902Definition) UnmarshalJSON(data []byte) error {
+	var rawDef struct {
+		Op    string
+		Path  *string
+		From  *string
+		Value json.RawMessage
+	}
+
+	err := json.Unmarshal(data, &rawDef)
+	if err != nil {
+		return err
+	}
+
+	*d = RFC6902Definition{Op: rawDef.Op, Path: rawDef.Path, From: rawDef.From}
+
+	if rawDef.Value != nil {
+		val, err := decodeJSONValue(rawDef.Value)
+		if err != nil {
+			return err
+		}
+		d.Value = &val
+	}
+
+	return nil
+}
+
+func decodeJSONValue(data []byte) (interface{}, error) {
+	var val interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	err := decoder.Decode(&val)
+	if err != nil {
+		return nil, err
+	}
+
+	return rfc6902ConvertValue(val), nil
+}
+
+func rfc6902ConvertValue(val interface{}) interface{} {
+	switch typedVal := val.(type) {
+	case map[string]interface{}:
+		result := map[interface{}]interface{}{}
+		for k, v := range typedVal {
+			result[k] = rfc6902ConvertValue(v)
+		}
+		return result
+
+	case []interface{}:
+		result := []interface{}{}
+		for _, v := range typedVal {
+			result = append(result, rfc6902ConvertValue(v))
+		}
+		return result
+
+	case json.Number:
+		if i, err := strconv.Atoi(typedVal.String()); err == nil {
+			return i
+		}
+		f, _ := typedVal.Float64()
+		return f
+
+	default:
+		return val
+	}
+}
+
+// NewOpsFromRFC6902 parses JSON encoded RFC 6902 document
+func NewOpsFromRFC6902(data []byte) (Ops, error) {
+	var defs []RFC6902Definition
+
+	err := json.Unmarshal(data, &defs)
+	if err != nil {
+		return nil, fmt.Errorf("Unmarshaling RFC 6902 document: %s", err)
+	}
+
+	return NewOpsFromRFC6902Definitions(defs)
+}
+
+func NewOpsFromRFC6902Definitions(defs []RFC6902Definition) (Ops, error) {
+	var ops []Op
+
+	for i, def := range defs {
+		defFmt := fmtRFC6902Def(def)
+
+		switch def.Op {
+		case "add", "remove", "replace", "move", "copy", "test":
+			op, err := newRFC6902Op(def)
+			if err != nil {
+				return nil, fmt.Errorf("RFC 6902 operation [%d] '%s': %s within\n%s", i, def.Op, err, defFmt)
+			}
+
+			ops = append(ops, op)
+
+		default:
+			return nil, fmt.Errorf("Unknown RFC 6902 operation [%d] with op '%s' within\n%s", i, def.Op, defFmt)
+		}
+	}
+
+	return Ops(ops), nil
+}
+
+func newRFC6902Op(def RFC6902Definition) (RFC6902Op, error) {
+	if def.Path == nil {
+		return RFC6902Op{}, fmt.Errorf("Missing path")
+	}
+
+	_, err := rfc6902ReferenceTokens(*def.Path)
+	if err != nil {
+		return RFC6902Op{}, fmt.Errorf("Invalid path: %s", err)
+	}
+
+	op := RFC6902Op{Op: def.Op, Path: *def.Path}
+
+	switch def.Op {
+	case "add", "replace", "test":
+		if def.Value == nil {
+			return RFC6902Op{}, fmt.Errorf("Missing value")
+		}
+		op.Value = *def.Value
+
+	case "move", "copy":
+		if def.From == nil {
+			return RFC6902Op{}, fmt.Errorf("Missing from path")
+		}
+
+		_, err := rfc6902ReferenceTokens(*def.From)
+		if err != nil {
+			return RFC6902Op{}, fmt.Errorf("Invalid from path: %s", err)
+		}
+
+		op.From = *def.From
+	}
+
+	return op, nil
+}
+
+func fmtRFC6902Def(def RFC6902Definition) string {
+	var redactedVal interface{} = "<redacted>"
+
+	if def.Value != nil {
+		// can't JSON serialize generic interface{} anyway
+		def.Value = &redactedVal
+	}
+
+	bytes, err := json.MarshalIndent(def, "", "  ")
+	if err != nil {
+		return "<unknown>"
+	}
+
+	return string(bytes)
+}
+
+// RFC6902Op applies single operation following RFC 6902 semantics exactly.
+// Path and From are plain RFC 6901 pointers (no go-patch extensions);
+// they are resolved against the document when operation is applied
+// since reference tokens refer to map keys or array indices depending on the document.
+type RFC6902Op struct {
+	Op    string
+	Path  string
+	From  string
+	Value interface{}
+}
+
+func (op RFC6902Op) Apply(doc interface{}) (interface{}, error) {
+	switch op.Op {
+	case "add":
+		return op.add(doc, op.Path, op.Value)
+
+	case "remove":
+		ptr, err := op.resolve(doc, op.Path, false)
+		if err != nil {
+			return nil, err
+		}
+
+		return RemoveOp{Path: ptr}.Apply(doc)
+
+	case "replace":
+		ptr, err := op.resolve(doc, op.Path, false)
+		if err != nil {
+			return nil, err
+		}
+
+		return ReplaceOp{Path: ptr, Value: op.Value}.Apply(doc)
+
+	case "move":
+		if op.From == op.Path {
+			return doc, nil
+		}
+
+		if strings.HasPrefix(op.Path, op.From+"/") {
+			return nil, fmt.Errorf("Expected path '%s' to not be a child of from path '%s'", op.Path, op.From)
+		}
+
+		fromPtr, err := op.resolve(doc, op.From, false)
+		if err != nil {
+			return nil, err
+		}
+
+		val, err := FindOp{Path: fromPtr}.Apply(doc)
+		if err != nil {
+			return nil, err
+		}
+
+		doc, err = RemoveOp{Path: fromPtr}.Apply(doc)
+		if err != nil {
+			return nil, err
+		}
+
+		return op.add(doc, op.Path, val)
+
+	case "copy":
+		fromPtr, err := op.resolve(doc, op.From, false)
+		if err != nil {
+			return nil, err
+		}
+
+		val, err := FindOp{Path: fromPtr}.Apply(doc)
+		if err != nil {
+			return nil, err
+		}
+
+		return op.add(doc, op.Path, val)
+
+	case "test":
+		ptr, err := op.resolve(doc, op.Path, false)
+		if err != nil {
+			return nil, err
+		}
+
+		return TestOp{Path: ptr, Value: op.Value}.Apply(doc)
+
+	default:
+		return nil, fmt.Errorf("Unknown RFC 6902 operation '%s'", op.Op)
+	}
+}
+
+func (op RFC6902Op) add(doc interface{}, path string, val interface{}) (interface{}, error) {
+	ptr, err := op.resolve(doc, path, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return ReplaceOp{Path: ptr, Value: val}.Apply(doc)
+}
+
+// resolve converts RFC 6901 pointer into concrete pointer for given document.
+// When adding, last reference token is allowed to refer to
+// a missing map key or to an array index right after the last item.
+func (RFC6902Op) resolve(doc interface{}, path string, adding bool) (Pointer, error) {
+	refTokens, err := rfc6902ReferenceTokens(path)
+	if err != nil {
+		return Pointer{}, err
+	}
+
+	tokens := []Token{RootToken{}}
+	obj := doc
+
+	for i, refToken := range refTokens {
+		isLast := i == len(refTokens)-1
+
+		switch typedObj := obj.(type) {
+		case map[interface{}]interface{}, map[string]interface{}:
+			objMap, _ := newGenericMap(obj)
+
+			if isLast && adding {
+				tokens = append(tokens, KeyToken{Key: refToken, Optional: true})
+				break
+			}
+
+			tokens = append(tokens, KeyToken{Key: refToken})
+
+			val, found := objMap.Get(refToken)
+			if !found {
+				return Pointer{}, newOpMissingMapKeyErr(refToken, NewPointer(tokens), objMap)
+			}
+
+			obj = val
+
+		case []interface{}:
+			if isLast && adding && refToken == "-" {
+				tokens = append(tokens, AfterLastIndexToken{})
+				break
+			}
+
+			idx, err := rfc6902ArrayIndex(refToken)
+			if err != nil {
+				return Pointer{}, fmt.Errorf("Expected to find array index at path '%s' but found '%s'",
+					NewPointer(tokens), refToken)
+			}
+
+			switch {
+			case isLast && adding && idx == len(typedObj):
+				tokens = append(tokens, AfterLastIndexToken{})
+
+			case idx >= len(typedObj):
+				tokens = append(tokens, IndexToken{Index: idx})
+				return Pointer{}, OpMissingIndexErr{idx, typedObj, NewPointer(tokens)}
+
+			case isLast && adding:
+				tokens = append(tokens, IndexToken{Index: idx, Modifiers: []Modifier{BeforeModifier{}}})
+
+			default:
+				tokens = append(tokens, IndexToken{Index: idx})
+				obj = typedObj[idx]
+			}
+
+		default:
+			return Pointer{}, OpMismatchTypeErr{"a map or an array", NewPointer(tokens), obj}
+		}
+	}
+
+	return NewPointer(tokens), nil
+}
+
+func rfc6902ReferenceTokens(str string) ([]string, error) {
+	if len(str) == 0 {
+		return nil, nil
+	}
+
+	if !strings.HasPrefix(str, "/") {
+		return nil, fmt.Errorf("Expected to start with '/'")
+	}
+
+	var tokens []string
+
+	for _, tok := range strings.Split(str, "/")[1:] {
+		tokens = append(tokens, rfc6902Decoder.Replace(tok))
+	}
+
+	return tokens, nil
+}
+
+func rfc6902ArrayIndex(str string) (int, error) {
+	// RFC 6901 does not allow leading zeros or signs
+	if len(str) == 0 || (len(str) > 1 && str[0] == '0') {
+		return 0, fmt.Errorf("Invalid array index '%s'", str)
+	}
+
+	for _, ch := range str {
+		if ch < '0' || ch > '9' {
+			return 0, fmt.Errorf("Invalid array index '%s'", str)
+		}
+	}
+
+	return strconv.Atoi(str)
+}
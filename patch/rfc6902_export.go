@@ -0,0 +1,711 @@
+package patch
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	rfc6902Encoder = strings.NewReplacer("~", "~0", "/", "~1")
+)
+
+// MarshalJSON converts maps with interface{} keys (produced by YAML library)
+// into JSON objects since they cannot be serialized by default
+func (d RFC6902Definition) MarshalJSON() ([]byte, error) {
+	type rfc6902Definition RFC6902Definition
+
+	if d.Value != nil {
+		val := jsonValue(*d.Value)
+		d.Value = &val
+	}
+
+	return json.Marshal(rfc6902Definition(d))
+}
+
+// NewRFC6902DefinitionsFromOps converts operations into RFC 6902 operations.
+// Pointers have to be expressible as plain RFC 6901 pointers; go-patch specific
+// tokens (matching index, optional keys, modifiers, wildcards, '-' in the middle
+// of a path, tagged map keys) result in an error.
+// Absence tests result in an error since RFC 6902 cannot express them.
+func NewRFC6902DefinitionsFromOps(ops Ops) ([]RFC6902Definition, error) {
+	return (&rfc6902Exporter{}).Export(ops)
+}
+
+// NewRFC6902DefinitionsFromOpsForDoc converts operations into RFC 6902 operations
+// resolving go-patch specific tokens to concrete keys and indices against given document.
+// Operations are applied one by one to a copy of the document so that
+// each operation is resolved against the result of previous operations.
+// Map keys that are not strings cannot be expressed and result in an error.
+// Absence tests are checked against the document and then omitted.
+func NewRFC6902DefinitionsFromOpsForDoc(ops Ops, doc interface{}) ([]RFC6902Definition, error) {
+	return (&rfc6902Exporter{doc: copyValue(doc), hasDoc: true}).Export(ops)
+}
+
+type rfc6902Exporter struct {
+	doc    interface{}
+	hasDoc bool
+}
+
+type rfc6902Target struct {
+	Op     string
+	Tokens []Token // consists of concrete key and index tokens
+	Append bool
+	Lookup []Token // location of added value in the resulting document if it differs from Tokens
+}
+
+func (e *rfc6902Exporter) Export(ops Ops) ([]RFC6902Definition, error) {
+	defs := []RFC6902Definition{}
+
+	for i, op := range e.flatten(ops) {
+		var opDefs []RFC6902Definition
+		var err error
+
+		if e.hasDoc {
+			opDefs, err = e.exportWithDoc(op)
+		} else {
+			opDefs, err = e.exportPlain(op)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Operation [%d]: %s", i, err)
+		}
+
+		defs = append(defs, opDefs...)
+	}
+
+	return defs, nil
+}
+
+func (e *rfc6902Exporter) flatten(ops Ops) []Op {
+	var result []Op
+
+	for _, op := range ops {
+		switch typedOp := op.(type) {
+		case Ops:
+			result = append(result, e.flatten(typedOp)...)
+		case DescriptiveOp:
+			result = append(result, e.flatten(Ops{typedOp.Op})...)
+		default:
+			result = append(result, op)
+		}
+	}
+
+	return result
+}
+
+func (e *rfc6902Exporter) exportPlain(op Op) ([]RFC6902Definition, error) {
+	switch typedOp := op.(type) {
+	case ReplaceOp:
+		target, err := e.plainWriteTarget(typedOp.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		return []RFC6902Definition{e.valueDef(target.Op, e.targetPath(target), typedOp.Value)}, nil
+
+	case RemoveOp:
+		path, err := e.plainPath(typedOp.Path, typedOp.Path.Tokens())
+		if err != nil {
+			return nil, err
+		}
+
+		return []RFC6902Definition{{Op: "remove", Path: &path}}, nil
+
+	case TestOp:
+		if typedOp.Absent {
+			return nil, fmt.Errorf("Expected to resolve path '%s' against a document (absence test cannot be expressed in RFC 6902)", typedOp.Path)
+		}
+
+		path, err := e.plainPath(typedOp.Path, typedOp.Path.Tokens())
+		if err != nil {
+			return nil, err
+		}
+
+		return []RFC6902Definition{e.valueDef("test", path, typedOp.Value)}, nil
+
+	case CopyOp:
+		def, err := e.plainCopy(typedOp.From, typedOp.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		return []RFC6902Definition{def}, nil
+
+	case MoveOp:
+		// Move operation first adds value to the new location and then removes it
+		def, err := e.plainCopy(typedOp.From, typedOp.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		from, err := e.plainMovedFrom(typedOp.From, typedOp.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		return []RFC6902Definition{def, {Op: "remove", Path: &from}}, nil
+
+	default:
+		return e.exportOther(op)
+	}
+}
+
+func (e *rfc6902Exporter) plainCopy(fromPtr, pathPtr Pointer) (RFC6902Definition, error) {
+	from, err := e.plainPath(fromPtr, fromPtr.Tokens())
+	if err != nil {
+		return RFC6902Definition{}, err
+	}
+
+	target, err := e.plainWriteTarget(pathPtr)
+	if err != nil {
+		return RFC6902Definition{}, err
+	}
+
+	tokens := pathPtr.Tokens()
+
+	// Replacing array item in place cannot be expressed via copy
+	if _, ok := tokens[len(tokens)-1].(IndexToken); ok && target.Op == "replace" {
+		return RFC6902Definition{}, fmt.Errorf(
+			"Expected to resolve path '%s' against a document (replacing array item with a copy)", pathPtr)
+	}
+
+	path := e.targetPath(target)
+
+	return RFC6902Definition{Op: "copy", From: &from, Path: &path}, nil
+}
+
+// plainMovedFrom returns path of the moved value after it was added to the new location
+// (adding an item before it into the same array shifts its index)
+func (e *rfc6902Exporter) plainMovedFrom(fromPtr, pathPtr Pointer) (string, error) {
+	target, err := e.plainWriteTarget(pathPtr)
+	if err != nil {
+		return "", err
+	}
+
+	fromTokens := append([]Token{}, fromPtr.Tokens()...)
+	parentTokens := target.Tokens
+
+	if !target.Append && len(target.Tokens) > 1 {
+		parentTokens = target.Tokens[:len(target.Tokens)-1]
+	}
+
+	if len(parentTokens) > 1 && isWithinPath(NewPointer(parentTokens), fromPtr) {
+		return "", moveOpWithinErr(pathPtr, fromPtr)
+	}
+
+	if target.Op == "add" && !target.Append && len(fromTokens) > len(parentTokens) &&
+		isWithinPath(fromPtr, NewPointer(parentTokens)) {
+
+		addedToken, addedOk := target.Tokens[len(target.Tokens)-1].(IndexToken)
+		fromToken, fromOk := fromTokens[len(parentTokens)].(IndexToken)
+
+		if addedOk && fromOk && fromToken.Index >= addedToken.Index {
+			fromTokens[len(parentTokens)] = IndexToken{Index: fromToken.Index + 1}
+		}
+	}
+
+	return e.targetPath(rfc6902Target{Tokens: fromTokens}), nil
+}
+
+func (e *rfc6902Exporter) plainWriteTarget(ptr Pointer) (rfc6902Target, error) {
+	tokens := ptr.Tokens()
+	if len(tokens) == 1 {
+		return rfc6902Target{Op: "replace", Tokens: tokens}, nil
+	}
+
+	_, err := e.plainPath(ptr, tokens[:len(tokens)-1])
+	if err != nil {
+		return rfc6902Target{}, err
+	}
+
+	parentTokens := append([]Token{}, tokens[:len(tokens)-1]...)
+
+	switch typedToken := tokens[len(tokens)-1].(type) {
+	case KeyToken:
+		if len(typedToken.Tag) > 0 {
+			break
+		}
+
+		op := "replace"
+		if typedToken.Optional {
+			op = "add"
+		}
+		return rfc6902Target{Op: op, Tokens: append(parentTokens, KeyToken{Key: typedToken.Key, Tag: typedToken.Tag})}, nil
+
+	case AfterLastIndexToken:
+		return rfc6902Target{Op: "add", Tokens: parentTokens, Append: true}, nil
+
+	case IndexToken:
+		if typedToken.Index >= 0 {
+			switch {
+			case len(typedToken.Modifiers) == 0:
+				return rfc6902Target{Op: "replace", Tokens: append(parentTokens, typedToken)}, nil
+
+			case len(typedToken.Modifiers) == 1:
+				switch typedToken.Modifiers[0].(type) {
+				case BeforeModifier:
+					return rfc6902Target{Op: "add", Tokens: append(parentTokens, IndexToken{Index: typedToken.Index})}, nil
+				case AfterModifier:
+					return rfc6902Target{Op: "add", Tokens: append(parentTokens, IndexToken{Index: typedToken.Index + 1})}, nil
+				}
+			}
+		}
+	}
+
+	return rfc6902Target{}, e.unresolvedErr(ptr, tokens[len(tokens)-1])
+}
+
+func (e *rfc6902Exporter) plainPath(ptr Pointer, tokens []Token) (string, error) {
+	for _, token := range tokens[1:] {
+		switch typedToken := token.(type) {
+		case KeyToken:
+			if !typedToken.Optional && len(typedToken.Tag) == 0 {
+				continue
+			}
+		case IndexToken:
+			if typedToken.Index >= 0 && len(typedToken.Modifiers) == 0 {
+				continue
+			}
+		}
+
+		return "", e.unresolvedErr(ptr, token)
+	}
+
+	return e.targetPath(rfc6902Target{Tokens: tokens}), nil
+}
+
+func (e *rfc6902Exporter) unresolvedErr(ptr Pointer, token Token) error {
+	return fmt.Errorf("Expected to resolve path '%s' against a document (found '%T' that cannot be expressed as RFC 6901 pointer)", ptr, token)
+}
+
+func (e *rfc6902Exporter) exportWithDoc(op Op) ([]RFC6902Definition, error) {
+	switch typedOp := op.(type) {
+	case CopyOp:
+		val, err := FindOp{Path: typedOp.From}.Apply(e.doc)
+		if err != nil {
+			return nil, err
+		}
+
+		return e.exportWithDoc(ReplaceOp{Path: typedOp.Path, Value: val})
+
+	case MoveOp:
+		// Markers keep track of moved values while the value is added to the new location
+		val, _, err := typedOp.markSources(e.doc)
+		if err != nil {
+			return nil, err
+		}
+
+		defs, err := e.exportWithDoc(ReplaceOp{Path: typedOp.Path, Value: val})
+		if err != nil {
+			return nil, err
+		}
+
+		markerPaths := movedMarkerPaths(e.doc, []Token{RootToken{}})
+
+		for i := len(markerPaths) - 1; i >= 0; i-- {
+			removeDefs, err := e.exportWithDoc(RemoveOp{Path: markerPaths[i]})
+			if err != nil {
+				return nil, err
+			}
+			defs = append(defs, removeDefs...)
+		}
+
+		return defs, nil
+
+	case SwapOp:
+		from, to, err := typedOp.resolve(e.doc)
+		if err != nil {
+			return nil, err
+		}
+
+		if from.Path.String() == to.Path.String() {
+			return nil, nil
+		}
+
+		return e.exportWithDoc(Ops{ReplaceOp{Path: from.Path, Value: to.Value}, ReplaceOp{Path: to.Path, Value: from.Value}})
+
+	case Ops:
+		var defs []RFC6902Definition
+		for _, op := range typedOp {
+			opDefs, err := e.exportWithDoc(op)
+			if err != nil {
+				return nil, err
+			}
+			defs = append(defs, opDefs...)
+		}
+		return defs, nil
+	}
+
+	prevDoc := e.doc
+
+	nextDoc, err := op.Apply(copyValue(prevDoc))
+	if err != nil {
+		return nil, err
+	}
+
+	e.doc = nextDoc
+
+	var defs []RFC6902Definition
+
+	switch typedOp := op.(type) {
+	case ReplaceOp:
+		targets, err := e.resolve(prevDoc, typedOp.Path.Tokens(), "write")
+		if err != nil {
+			return nil, err
+		}
+
+		for _, target := range targets {
+			lookupTokens := target.Tokens
+			if target.Lookup != nil {
+				lookupTokens = target.Lookup
+			} else if target.Append {
+				arr, err := FindOp{Path: NewPointer(target.Tokens)}.Apply(prevDoc)
+				if err != nil {
+					return nil, err
+				}
+				lookupTokens = append(append([]Token{}, target.Tokens...), IndexToken{Index: len(arr.([]interface{}))})
+			}
+
+			val, err := FindOp{Path: NewPointer(lookupTokens)}.Apply(nextDoc)
+			if err != nil {
+				return nil, err
+			}
+
+			defs = append(defs, e.valueDef(target.Op, e.targetPath(target), val))
+		}
+
+	case RemoveOp:
+		targets, err := e.resolve(prevDoc, typedOp.Path.Tokens(), "remove")
+		if err != nil {
+			return nil, err
+		}
+
+		for _, target := range targets {
+			path := e.targetPath(target)
+			defs = append(defs, RFC6902Definition{Op: "remove", Path: &path})
+		}
+
+	case TestOp:
+		if typedOp.Absent {
+			return nil, nil
+		}
+
+		targets, err := e.resolve(prevDoc, typedOp.Path.Tokens(), "read")
+		if err != nil {
+			return nil, err
+		}
+
+		for _, target := range targets {
+			defs = append(defs, e.valueDef("test", e.targetPath(target), typedOp.Value))
+		}
+
+	default:
+		return e.exportOther(op)
+	}
+
+	return defs, nil
+}
+
+func (e *rfc6902Exporter) exportOther(op Op) ([]RFC6902Definition, error) {
+	switch typedOp := op.(type) {
+	case RFC6902Op:
+		def := RFC6902Definition{Op: typedOp.Op}
+
+		path := typedOp.Path
+		def.Path = &path
+
+		switch typedOp.Op {
+		case "move", "copy":
+			from := typedOp.From
+			def.From = &from
+		case "add", "replace", "test":
+			val := typedOp.Value
+			def.Value = &val
+		}
+
+		return []RFC6902Definition{def}, nil
+
+	default:
+		return nil, fmt.Errorf("Expected to find operation that can be expressed in RFC 6902 but found '%T'", op)
+	}
+}
+
+// resolve walks document and returns concrete targets for given tokens.
+// Modes: 'read' requires all tokens to exist, 'remove' skips missing
+// optional tokens, and 'write' returns first optionally missing location
+// (its whole value needs to be added).
+func (e *rfc6902Exporter) resolve(obj interface{}, tokens []Token, mode string) ([]rfc6902Target, error) {
+	type resolveCtx struct {
+		Obj    interface{}
+		I      int
+		Tokens []Token
+	}
+
+	var targets []rfc6902Target
+
+	leafOp := map[string]string{"read": "test", "remove": "remove", "write": "replace"}[mode]
+
+	if len(tokens) == 1 {
+		if mode == "remove" {
+			return nil, fmt.Errorf("Cannot remove entire document")
+		}
+		return []rfc6902Target{{Op: leafOp, Tokens: tokens}}, nil
+	}
+
+	ctxStack := []resolveCtx{{Obj: obj, I: 0, Tokens: []Token{RootToken{}}}}
+
+	for len(ctxStack) != 0 {
+		// Process contexts in document order
+		ctx := ctxStack[0]
+		ctxStack = ctxStack[1:]
+
+		token := tokens[ctx.I+1]
+		isLast := ctx.I == len(tokens)-2
+		currPath := NewPointer(tokens[:ctx.I+2])
+
+		token = afterLastIndexKey(token, ctx.Obj, isLast)
+
+		var nextCtxs []resolveCtx
+
+		addTarget := func(op string, token Token, appending bool) {
+			targetTokens := append([]Token{}, ctx.Tokens...)
+			if token != nil {
+				targetTokens = append(targetTokens, token)
+			}
+			targets = append(targets, rfc6902Target{Op: op, Tokens: targetTokens, Append: appending})
+		}
+
+		// addInsertTarget adds array item at index of the current document
+		// which ends up at lookupIdx after all insertions are applied
+		addInsertTarget := func(idx, lookupIdx int, appending bool) {
+			var token Token = IndexToken{Index: idx}
+			if appending {
+				token = nil
+			}
+			addTarget("add", token, appending)
+			targets[len(targets)-1].Lookup = append(append([]Token{}, ctx.Tokens...), IndexToken{Index: lookupIdx})
+		}
+
+		addNext := func(token Token, obj interface{}) {
+			nextTokens := append(append([]Token{}, ctx.Tokens...), token)
+			if isLast {
+				targets = append(targets, rfc6902Target{Op: leafOp, Tokens: nextTokens})
+			} else {
+				nextCtxs = append(nextCtxs, resolveCtx{Obj: obj, I: ctx.I + 1, Tokens: nextTokens})
+			}
+		}
+
+		switch typedToken := token.(type) {
+		case KeyToken:
+			typedObj, ok := newGenericMap(ctx.Obj)
+			if !ok {
+				return nil, NewOpMapMismatchTypeErr(currPath, ctx.Obj)
+			}
+
+			val, found := typedObj.Get(typedToken.mapKey())
+			if !found {
+				switch {
+				case mode == "write" && typedToken.Optional:
+					addTarget("add", KeyToken{Key: typedToken.Key, Tag: typedToken.Tag}, false)
+				case mode == "remove" && typedToken.Optional:
+					// nothing to remove
+				default:
+					return nil, newOpMissingMapKeyErr(typedToken.Key, currPath, typedObj)
+				}
+				break
+			}
+
+			addNext(KeyToken{Key: typedToken.Key, Tag: typedToken.Tag}, val)
+
+		case IndexToken:
+			typedObj, ok := ctx.Obj.([]interface{})
+			if !ok {
+				return nil, NewOpArrayMismatchTypeErr(currPath, ctx.Obj)
+			}
+
+			if isLast && mode == "write" {
+				idx, err := ArrayInsertion{Index: typedToken.Index, Modifiers: typedToken.Modifiers, Array: typedObj, Path: currPath}.Concrete()
+				if err != nil {
+					return nil, err
+				}
+
+				if idx.insert {
+					addTarget("add", IndexToken{Index: idx.number}, false)
+				} else {
+					addNext(IndexToken{Index: idx.number}, nil)
+				}
+				break
+			}
+
+			idx, err := ArrayIndex{Index: typedToken.Index, Modifiers: typedToken.Modifiers, Array: typedObj, Path: currPath}.Concrete()
+			if err != nil {
+				return nil, err
+			}
+
+			addNext(IndexToken{Index: idx}, typedObj[idx])
+
+		case AfterLastIndexToken:
+			if mode != "write" {
+				return nil, OpUnexpectedTokenErr{token, currPath}
+			}
+
+			if _, ok := ctx.Obj.([]interface{}); !ok {
+				return nil, NewOpArrayMismatchTypeErr(currPath, ctx.Obj)
+			}
+
+			// Appended item is added as a whole even if it's not last
+			addTarget("add", nil, true)
+
+		case MatchingIndexToken:
+			typedObj, ok := ctx.Obj.([]interface{})
+			if !ok {
+				return nil, NewOpArrayMismatchTypeErr(currPath, ctx.Obj)
+			}
+
+			idxs := matchingIndexes(typedToken, typedObj)
+
+			if typedToken.Optional && len(idxs) == 0 {
+				switch mode {
+				case "write":
+					pos, err := missingMatchingPosition(typedToken, typedObj, currPath)
+					if err != nil {
+						return nil, err
+					}
+
+					addInsertTarget(pos, pos, pos == len(typedObj))
+				case "remove":
+					// nothing to remove
+				default:
+					return nil, OpMultipleMatchingIndexErr{currPath, idxs}
+				}
+				break
+			}
+
+			idxs, modifiers, err := selectMatchingIndexes(typedToken, idxs, currPath)
+			if err != nil {
+				return nil, err
+			}
+
+			if isLast && mode == "write" {
+				var insertions []ArrayInsertionIndex
+
+				for _, idx := range idxs {
+					idx, err := ArrayInsertion{Index: idx, Modifiers: modifiers, Array: typedObj, Path: currPath}.Concrete()
+					if err != nil {
+						return nil, err
+					}
+
+					insertions = append(insertions, idx)
+				}
+
+				// Add items starting from the end so that insertions do not shift indices
+				for i := len(insertions) - 1; i >= 0; i-- {
+					idx := insertions[i]
+
+					if !idx.insert {
+						addNext(IndexToken{Index: idx.number}, nil)
+						continue
+					}
+
+					// Items added afterwards in front of this item shift it in the resulting document
+					lookupIdx := idx.number
+					for j := i - 1; j >= 0; j-- {
+						if insertions[j].insert && insertions[j].number <= lookupIdx {
+							lookupIdx++
+						}
+					}
+
+					addInsertTarget(idx.number, lookupIdx, false)
+				}
+				break
+			}
+
+			for _, idx := range idxs {
+				idx, err := ArrayIndex{Index: idx, Modifiers: modifiers, Array: typedObj, Path: currPath}.Concrete()
+				if err != nil {
+					return nil, err
+				}
+
+				addNext(IndexToken{Index: idx}, typedObj[idx])
+			}
+
+		case WildcardToken:
+			if typedObj, ok := newGenericMap(ctx.Obj); ok {
+				for _, key := range sortedKeys(typedObj) {
+					val, _ := typedObj.Get(key)
+					addNext(newKeyToken(key), val)
+				}
+				break
+			}
+
+			typedObj, ok := ctx.Obj.([]interface{})
+			if !ok {
+				return nil, OpMismatchTypeErr{"a map or an array", currPath, ctx.Obj}
+			}
+
+			for idx, o := range typedObj {
+				addNext(IndexToken{Index: idx}, o)
+			}
+
+		case KeyGlobToken:
+			typedObj, ok := newGenericMap(ctx.Obj)
+			if !ok {
+				return nil, NewOpMapMismatchTypeErr(currPath, ctx.Obj)
+			}
+
+			for _, key := range matchingKeys(typedToken, typedObj) {
+				val, _ := typedObj.Get(key)
+				addNext(KeyToken{Key: key}, val)
+			}
+
+		default:
+			return nil, OpUnexpectedTokenErr{token, currPath}
+		}
+
+		ctxStack = append(nextCtxs, ctxStack...)
+	}
+
+	for _, target := range targets {
+		for _, token := range target.Tokens {
+			if keyToken, ok := token.(KeyToken); ok && len(keyToken.Tag) > 0 {
+				errMsg := "Expected to find string map keys in path '%s' but found '%s %s' (cannot be expressed as RFC 6901 pointer)"
+				return nil, fmt.Errorf(errMsg, NewPointer(target.Tokens), keyToken.Tag, keyToken.Key)
+			}
+		}
+	}
+
+	if mode == "remove" {
+		// Remove array items starting from the end so that indices stay valid
+		for i, j := 0, len(targets)-1; i < j; i, j = i+1, j-1 {
+			targets[i], targets[j] = targets[j], targets[i]
+		}
+	}
+
+	return targets, nil
+}
+
+func (e *rfc6902Exporter) valueDef(op string, path string, val interface{}) RFC6902Definition {
+	return RFC6902Definition{Op: op, Path: &path, Value: &val}
+}
+
+func (e *rfc6902Exporter) targetPath(target rfc6902Target) string {
+	var strs []string
+
+	for _, token := range target.Tokens {
+		switch typedToken := token.(type) {
+		case RootToken:
+			strs = append(strs, "")
+		case KeyToken:
+			strs = append(strs, rfc6902Encoder.Replace(typedToken.Key))
+		case IndexToken:
+			strs = append(strs, strconv.Itoa(typedToken.Index))
+		}
+	}
+
+	if target.Append {
+		strs = append(strs, "-")
+	}
+
+	return strings.Join(strs, "/")
+}
@@ -0,0 +1,353 @@
+package patch_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/stuart-pollock/go-patch/patch"
+)
+
+var _ = Describe("NewRFC6902DefinitionsFromOps", func() {
+	toJSON := func(defs []RFC6902Definition) string {
+		bs, err := json.Marshal(defs)
+		Expect(err).ToNot(HaveOccurred())
+		return string(bs)
+	}
+
+	It("converts operations with plain pointers", func() {
+		defs, err := NewRFC6902DefinitionsFromOps(Ops{
+			ReplaceOp{Path: MustNewPointerFromString("/a/0/b"), Value: map[interface{}]interface{}{"c": 1}},
+			ReplaceOp{Path: MustNewPointerFromString("/a/new?"), Value: nil},
+			ReplaceOp{Path: MustNewPointerFromString("/a/-"), Value: 1},
+			ReplaceOp{Path: MustNewPointerFromString("/a/1:before"), Value: 2},
+			ReplaceOp{Path: MustNewPointerFromString("/a/1:after"), Value: 3},
+			ReplaceOp{Path: MustNewPointerFromString(""), Value: 4},
+			RemoveOp{Path: MustNewPointerFromString("/a~1b/m~0n")},
+			TestOp{Path: MustNewPointerFromString("/a"), Value: "a"},
+			CopyOp{From: MustNewPointerFromString("/a"), Path: MustNewPointerFromString("/b/-")},
+			MoveOp{From: MustNewPointerFromString("/a"), Path: MustNewPointerFromString("/b?")},
+			DescriptiveOp{Op: Ops{RemoveOp{Path: MustNewPointerFromString("/c")}}, ErrorMsg: "msg"},
+			RFC6902Op{Op: "add", Path: "/d", Value: 1},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(toJSON(defs)).To(MatchJSON(`[
+  {"op": "replace", "path": "/a/0/b", "value": {"c": 1}},
+  {"op": "add", "path": "/a/new", "value": null},
+  {"op": "add", "path": "/a/-", "value": 1},
+  {"op": "add", "path": "/a/1", "value": 2},
+  {"op": "add", "path": "/a/2", "value": 3},
+  {"op": "replace", "path": "", "value": 4},
+  {"op": "remove", "path": "/a~1b/m~0n"},
+  {"op": "test", "path": "/a", "value": "a"},
+  {"op": "copy", "from": "/a", "path": "/b/-"},
+  {"op": "copy", "from": "/a", "path": "/b"},
+  {"op": "remove", "path": "/a"},
+  {"op": "remove", "path": "/c"},
+  {"op": "add", "path": "/d", "value": 1}
+]`))
+	})
+
+	It("converts diff output", func() {
+		left := map[interface{}]interface{}{"a": []interface{}{1, 2}, "b": "b"}
+		right := map[interface{}]interface{}{"a": []interface{}{1, 3, 4}, "c": "c"}
+
+		defs, err := NewRFC6902DefinitionsFromOpsForDoc(Diff{Left: left, Right: right}.Calculate(), left)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(toJSON(defs)).To(MatchJSON(`[
+  {"op": "test", "path": "/a/1", "value": 2},
+  {"op": "replace", "path": "/a/1", "value": 3},
+  {"op": "add", "path": "/a/-", "value": 4},
+  {"op": "test", "path": "/b", "value": "b"},
+  {"op": "remove", "path": "/b"},
+  {"op": "add", "path": "/c", "value": "c"}
+]`))
+
+		ops, err := NewOpsFromRFC6902([]byte(toJSON(defs)))
+		Expect(err).ToNot(HaveOccurred())
+
+		res, err := ops.Apply(left)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(right))
+	})
+
+	It("returns error for absence tests without a document", func() {
+		_, err := NewRFC6902DefinitionsFromOps(Ops{TestOp{Path: MustNewPointerFromString("/a"), Absent: true}})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal(
+			"Operation [0]: Expected to resolve path '/a' against a document (absence test cannot be expressed in RFC 6902)"))
+
+		left := map[interface{}]interface{}{"a": 1}
+		right := map[interface{}]interface{}{"a": 1, "b": 2}
+
+		_, err = NewRFC6902DefinitionsFromOps(Diff{Left: left, Right: right}.Calculate())
+		Expect(err).To(HaveOccurred())
+
+		defs, err := NewRFC6902DefinitionsFromOps(Diff{Left: left, Right: right, Unchecked: true}.Calculate())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(toJSON(defs)).To(MatchJSON(`[{"op": "add", "path": "/b", "value": 2}]`))
+	})
+
+	It("returns error for go-patch specific tokens", func() {
+		for _, path := range []string{"/a?/b", "/name=a", "/*/a", "/-1", "/0:next", "/a/0:before:prev", "/items/-/name", "/m/!!int 80", "/m/!!int 80/a"} {
+			_, err := NewRFC6902DefinitionsFromOps(Ops{ReplaceOp{Path: MustNewPointerFromString(path), Value: 1}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Operation [0]: Expected to resolve path '" + path + "' against a document"))
+		}
+
+		_, err := NewRFC6902DefinitionsFromOps(Ops{RemoveOp{Path: MustNewPointerFromString("/a?")}})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Operation [0]: Expected to resolve path '/a?' against a document (found 'patch.KeyToken' that cannot be expressed as RFC 6901 pointer)"))
+
+		_, err = NewRFC6902DefinitionsFromOps(Ops{ReplaceOp{Path: MustNewPointerFromString("/items/-/name"), Value: 1}})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Operation [0]: Expected to resolve path '/items/-/name' against a document (found 'patch.AfterLastIndexToken' that cannot be expressed as RFC 6901 pointer)"))
+
+		_, err = NewRFC6902DefinitionsFromOps(Ops{TestOp{Path: MustNewPointerFromString("/m/!!int 80"), Value: 1}})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Operation [0]: Expected to resolve path '/m/!!int 80' against a document (found 'patch.KeyToken' that cannot be expressed as RFC 6901 pointer)"))
+	})
+
+	It("returns error when copying into existing array item", func() {
+		_, err := NewRFC6902DefinitionsFromOps(Ops{CopyOp{From: MustNewPointerFromString("/a"), Path: MustNewPointerFromString("/b/0")}})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Operation [0]: Expected to resolve path '/b/0' against a document (replacing array item with a copy)"))
+	})
+
+	It("removes moved array item at its shifted index", func() {
+		defs, err := NewRFC6902DefinitionsFromOps(Ops{
+			MoveOp{From: MustNewPointerFromString("/a/3"), Path: MustNewPointerFromString("/a/0:before")},
+			MoveOp{From: MustNewPointerFromString("/a/0"), Path: MustNewPointerFromString("/a/2:after")},
+			MoveOp{From: MustNewPointerFromString("/a/1/b"), Path: MustNewPointerFromString("/a/1:before")},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(toJSON(defs)).To(MatchJSON(`[
+  {"op": "copy", "from": "/a/3", "path": "/a/0"},
+  {"op": "remove", "path": "/a/4"},
+  {"op": "copy", "from": "/a/0", "path": "/a/3"},
+  {"op": "remove", "path": "/a/0"},
+  {"op": "copy", "from": "/a/1/b", "path": "/a/1"},
+  {"op": "remove", "path": "/a/2/b"}
+]`))
+
+		_, err = NewRFC6902DefinitionsFromOps(Ops{MoveOp{From: MustNewPointerFromString("/a/0"), Path: MustNewPointerFromString("/a/0/b/-")}})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Operation [0]: Expected path '/a/0/b/-' to not be within moved value at path '/a/0'"))
+	})
+
+	It("returns error for operations that cannot be expressed", func() {
+		_, err := NewRFC6902DefinitionsFromOps(Ops{FindOp{Path: MustNewPointerFromString("/a")}})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Operation [0]: Expected to find operation that can be expressed in RFC 6902 but found 'patch.FindOp'"))
+	})
+})
+
+var _ = Describe("NewRFC6902DefinitionsFromOpsForDoc", func() {
+	var doc interface{}
+
+	BeforeEach(func() {
+		doc = map[interface{}]interface{}{
+			"instance_groups": []interface{}{
+				map[interface{}]interface{}{
+					"name":      "api",
+					"instances": 1,
+					"jobs":      []interface{}{map[interface{}]interface{}{"name": "capi"}},
+				},
+				map[interface{}]interface{}{
+					"name":      "worker",
+					"instances": 2,
+					"jobs":      []interface{}{map[interface{}]interface{}{"name": "worker"}},
+				},
+			},
+		}
+	})
+
+	convert := func(ops Ops) string {
+		defs, err := NewRFC6902DefinitionsFromOpsForDoc(ops, doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		bs, err := json.Marshal(defs)
+		Expect(err).ToNot(HaveOccurred())
+
+		// Applying RFC 6902 operations produces the same result as original operations
+		rfcOps, err := NewOpsFromRFC6902(bs)
+		Expect(err).ToNot(HaveOccurred())
+
+		// Replacing entire document clones it
+		rfcDoc, err := ReplaceOp{Path: MustNewPointerFromString(""), Value: doc}.Apply(nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		rfcRes, err := rfcOps.Apply(rfcDoc)
+		Expect(err).ToNot(HaveOccurred())
+
+		opsDoc, err := ReplaceOp{Path: MustNewPointerFromString(""), Value: doc}.Apply(nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		res, err := ops.Apply(opsDoc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rfcRes).To(Equal(res))
+
+		return string(bs)
+	}
+
+	It("resolves matching index tokens and modifiers", func() {
+		Expect(convert(Ops{
+			ReplaceOp{Path: MustNewPointerFromString("/instance_groups/name=worker/instances"), Value: 3},
+			ReplaceOp{Path: MustNewPointerFromString("/instance_groups/name=api:after"), Value: "new"},
+			ReplaceOp{Path: MustNewPointerFromString("/instance_groups/-1:prev"), Value: "new2"},
+			RemoveOp{Path: MustNewPointerFromString("/instance_groups/name=api/jobs/name=capi")},
+			TestOp{Path: MustNewPointerFromString("/instance_groups/name=api/jobs"), Value: []interface{}{}},
+		})).To(MatchJSON(`[
+  {"op": "replace", "path": "/instance_groups/1/instances", "value": 3},
+  {"op": "add", "path": "/instance_groups/1", "value": "new"},
+  {"op": "replace", "path": "/instance_groups/1", "value": "new2"},
+  {"op": "remove", "path": "/instance_groups/0/jobs/0"},
+  {"op": "test", "path": "/instance_groups/0/jobs", "value": []}
+]`))
+	})
+
+	It("resolves optional tokens by adding created values", func() {
+		Expect(convert(Ops{
+			ReplaceOp{Path: MustNewPointerFromString("/instance_groups/name=api/env?/bosh/password"), Value: "pass"},
+			ReplaceOp{Path: MustNewPointerFromString("/instance_groups/name=errand?/jobs/-"), Value: "job"},
+			ReplaceOp{Path: MustNewPointerFromString("/instance_groups/name=api/instances?"), Value: 5},
+			RemoveOp{Path: MustNewPointerFromString("/instance_groups/name=api/missing?")},
+			RemoveOp{Path: MustNewPointerFromString("/instance_groups/name=missing?")},
+		})).To(MatchJSON(`[
+  {"op": "add", "path": "/instance_groups/0/env", "value": {"bosh": {"password": "pass"}}},
+  {"op": "add", "path": "/instance_groups/-", "value": {"name": "errand", "jobs": ["job"]}},
+  {"op": "replace", "path": "/instance_groups/0/instances", "value": 5}
+]`))
+	})
+
+	It("resolves '-' in the middle of a path by adding appended item", func() {
+		Expect(convert(Ops{
+			ReplaceOp{Path: MustNewPointerFromString("/instance_groups/-/name"), Value: "errand"},
+			ReplaceOp{Path: MustNewPointerFromString("/instance_groups/name=api/jobs/-/name"), Value: "uaa"},
+		})).To(MatchJSON(`[
+  {"op": "add", "path": "/instance_groups/-", "value": {"name": "errand"}},
+  {"op": "add", "path": "/instance_groups/0/jobs/-", "value": {"name": "uaa"}}
+]`))
+	})
+
+	It("returns error for map keys that are not strings", func() {
+		doc := map[interface{}]interface{}{"ports": map[interface{}]interface{}{80: "a"}}
+
+		_, err := NewRFC6902DefinitionsFromOpsForDoc(Ops{ReplaceOp{Path: MustNewPointerFromString("/ports/!!int 80"), Value: "b"}}, doc)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Operation [0]: Expected to find string map keys in path '/ports/!!int 80' but found '!!int 80' (cannot be expressed as RFC 6901 pointer)"))
+
+		_, err = NewRFC6902DefinitionsFromOpsForDoc(Ops{RemoveOp{Path: MustNewPointerFromString("/ports/*")}}, doc)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Operation [0]: Expected to find string map keys in path '/ports/!!int 80' but found '!!int 80' (cannot be expressed as RFC 6901 pointer)"))
+	})
+
+	It("resolves items added next to all matching items", func() {
+		doc = map[interface{}]interface{}{
+			"jobs": []interface{}{
+				map[interface{}]interface{}{"name": "x", "type": "a"},
+				map[interface{}]interface{}{"name": "y", "type": "b"},
+				map[interface{}]interface{}{"name": "z", "type": "a"},
+				map[interface{}]interface{}{"name": "w", "type": "a"},
+			},
+		}
+
+		Expect(convert(Ops{
+			ReplaceOp{Path: MustNewPointerFromString("/jobs/type=a:all:before"), Value: "before"},
+			ReplaceOp{Path: MustNewPointerFromString("/jobs/name=x:all:after"), Value: "after"},
+		})).To(MatchJSON(`[
+  {"op": "add", "path": "/jobs/3", "value": "before"},
+  {"op": "add", "path": "/jobs/2", "value": "before"},
+  {"op": "add", "path": "/jobs/0", "value": "before"},
+  {"op": "add", "path": "/jobs/2", "value": "after"}
+]`))
+	})
+
+	It("resolves missing optional matching items added next to other items", func() {
+		Expect(convert(Ops{
+			ReplaceOp{Path: MustNewPointerFromString("/instance_groups/name=errand?:before=name=worker/instances"), Value: 1},
+			ReplaceOp{Path: MustNewPointerFromString("/instance_groups/name=smoke?:after=name=api"), Value: map[interface{}]interface{}{"name": "smoke"}},
+			ReplaceOp{Path: MustNewPointerFromString("/instance_groups/name=first?:before/jobs/-"), Value: "job"},
+			ReplaceOp{Path: MustNewPointerFromString("/instance_groups/name=last?:after=name=missing/instances"), Value: 2},
+		})).To(MatchJSON(`[
+  {"op": "add", "path": "/instance_groups/1", "value": {"name": "errand", "instances": 1}},
+  {"op": "add", "path": "/instance_groups/1", "value": {"name": "smoke"}},
+  {"op": "add", "path": "/instance_groups/0", "value": {"name": "first", "jobs": ["job"]}},
+  {"op": "add", "path": "/instance_groups/-", "value": {"name": "last", "instances": 2}}
+]`))
+	})
+
+	It("resolves wildcards", func() {
+		Expect(convert(Ops{
+			ReplaceOp{Path: MustNewPointerFromString("/instance_groups/*/instances"), Value: 0},
+			RemoveOp{Path: MustNewPointerFromString("/instance_groups/*/jobs/0")},
+		})).To(MatchJSON(`[
+  {"op": "replace", "path": "/instance_groups/0/instances", "value": 0},
+  {"op": "replace", "path": "/instance_groups/1/instances", "value": 0},
+  {"op": "remove", "path": "/instance_groups/1/jobs/0"},
+  {"op": "remove", "path": "/instance_groups/0/jobs/0"}
+]`))
+	})
+
+	It("resolves copy and move operations", func() {
+		Expect(convert(Ops{
+			CopyOp{From: MustNewPointerFromString("/instance_groups/name=api/jobs/name=capi"), Path: MustNewPointerFromString("/instance_groups/name=worker/jobs/0")},
+			MoveOp{From: MustNewPointerFromString("/instance_groups/name=api/instances"), Path: MustNewPointerFromString("/instance_groups/name=worker/count?")},
+		})).To(MatchJSON(`[
+  {"op": "replace", "path": "/instance_groups/1/jobs/0", "value": {"name": "capi"}},
+  {"op": "add", "path": "/instance_groups/1/count", "value": 1},
+  {"op": "remove", "path": "/instance_groups/0/instances"}
+]`))
+	})
+
+	It("resolves move and swap operations within the same array", func() {
+		Expect(convert(Ops{
+			MoveOp{From: MustNewPointerFromString("/instance_groups/name=worker"), Path: MustNewPointerFromString("/instance_groups/name=api:before")},
+		})).To(MatchJSON(`[
+  {"op": "add", "path": "/instance_groups/0", "value": {"name": "worker", "instances": 2, "jobs": [{"name": "worker"}]}},
+  {"op": "remove", "path": "/instance_groups/2"}
+]`))
+
+		Expect(convert(Ops{
+			SwapOp{From: MustNewPointerFromString("/instance_groups/name=api/instances"), Path: MustNewPointerFromString("/instance_groups/name=worker/instances")},
+		})).To(MatchJSON(`[
+  {"op": "replace", "path": "/instance_groups/0/instances", "value": 2},
+  {"op": "replace", "path": "/instance_groups/1/instances", "value": 1}
+]`))
+	})
+
+	It("checks and omits absence tests", func() {
+		Expect(convert(Ops{
+			TestOp{Path: MustNewPointerFromString("/instance_groups/name=api/missing"), Absent: true},
+		})).To(MatchJSON(`[]`))
+
+		_, err := NewRFC6902DefinitionsFromOpsForDoc(Ops{
+			TestOp{Path: MustNewPointerFromString("/instance_groups/name=api/name"), Absent: true},
+		}, doc)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Operation [0]: Expected to not find '/instance_groups/name=api/name'"))
+	})
+
+	It("does not modify given document", func() {
+		_, err := NewRFC6902DefinitionsFromOpsForDoc(Ops{
+			ReplaceOp{Path: MustNewPointerFromString("/instance_groups/name=api/instances"), Value: 10},
+		}, doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		res, err := FindOp{Path: MustNewPointerFromString("/instance_groups/name=api/instances")}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(1))
+	})
+
+	It("returns error if operation fails", func() {
+		_, err := NewRFC6902DefinitionsFromOpsForDoc(Ops{
+			ReplaceOp{Path: MustNewPointerFromString("/instance_groups/name=missing/instances"), Value: 10},
+		}, doc)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Operation [0]: Expected to find exactly one matching array item for path '/instance_groups/name=missing' but found 0"))
+	})
+})
@@ -0,0 +1,270 @@
+package patch_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/stuart-pollock/go-patch/patch"
+)
+
+var _ = Describe("NewOpsFromRFC6902", func() {
+	It("parses all RFC 6902 operations", func() {
+		ops, err := NewOpsFromRFC6902([]byte(`[
+  { "op": "test", "path": "/a/b/c", "value": "foo" },
+  { "op": "remove", "path": "/a/b/c" },
+  { "op": "add", "path": "/a/b/c", "value": [ "foo", "bar" ] },
+  { "op": "replace", "path": "/a/b/c", "value": {"d": 42, "e": 1.5, "f": null} },
+  { "op": "move", "from": "/a/b/c", "path": "/a/b/d" },
+  { "op": "copy", "from": "/a/b/d", "path": "/a/b/e" },
+  { "op": "add", "path": "/a/b/f", "value": null }
+]`))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(ops).To(Equal(Ops([]Op{
+			RFC6902Op{Op: "test", Path: "/a/b/c", Value: "foo"},
+			RFC6902Op{Op: "remove", Path: "/a/b/c"},
+			RFC6902Op{Op: "add", Path: "/a/b/c", Value: []interface{}{"foo", "bar"}},
+			RFC6902Op{Op: "replace", Path: "/a/b/c", Value: map[interface{}]interface{}{"d": 42, "e": 1.5, "f": nil}},
+			RFC6902Op{Op: "move", From: "/a/b/c", Path: "/a/b/d"},
+			RFC6902Op{Op: "copy", From: "/a/b/d", Path: "/a/b/e"},
+			RFC6902Op{Op: "add", Path: "/a/b/f", Value: nil},
+		})))
+	})
+
+	It("returns error if document is not valid JSON", func() {
+		_, err := NewOpsFromRFC6902([]byte(`{`))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Unmarshaling RFC 6902 document"))
+	})
+
+	It("returns error if operation is unknown", func() {
+		_, err := NewOpsFromRFC6902([]byte(`[{"op": "find", "path": "/a"}]`))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal(`Unknown RFC 6902 operation [0] with op 'find' within
+{
+  "op": "find",
+  "path": "/a"
+}`))
+	})
+
+	It("returns error if path is missing or invalid", func() {
+		_, err := NewOpsFromRFC6902([]byte(`[{"op": "remove"}]`))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal(`RFC 6902 operation [0] 'remove': Missing path within
+{
+  "op": "remove"
+}`))
+
+		_, err = NewOpsFromRFC6902([]byte(`[{"op": "remove", "path": "a"}]`))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Invalid path: Expected to start with '/'"))
+	})
+
+	It("returns error if value is missing", func() {
+		for _, op := range []string{"add", "replace", "test"} {
+			_, err := NewOpsFromRFC6902([]byte(`[{"op": "` + op + `", "path": "/a"}]`))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("'" + op + "': Missing value within"))
+		}
+	})
+
+	It("returns error if from is missing or invalid", func() {
+		for _, op := range []string{"move", "copy"} {
+			_, err := NewOpsFromRFC6902([]byte(`[{"op": "` + op + `", "path": "/a"}]`))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("'" + op + "': Missing from path within"))
+
+			_, err = NewOpsFromRFC6902([]byte(`[{"op": "` + op + `", "from": "a", "path": "/a"}]`))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("'" + op + "': Invalid from path: Expected to start with '/'"))
+		}
+	})
+})
+
+var _ = Describe("RFC6902Op.Apply", func() {
+	apply := func(doc interface{}, patch string) (interface{}, error) {
+		ops, err := NewOpsFromRFC6902([]byte(patch))
+		Expect(err).ToNot(HaveOccurred())
+		return ops.Apply(doc)
+	}
+
+	Describe("add", func() {
+		It("adds or replaces map key", func() {
+			res, err := apply(map[interface{}]interface{}{"foo": "bar"},
+				`[{"op": "add", "path": "/baz", "value": "qux"}, {"op": "add", "path": "/foo", "value": 1}]`)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{"foo": 1, "baz": "qux"}))
+		})
+
+		It("inserts array items", func() {
+			res, err := apply(map[interface{}]interface{}{"foo": []interface{}{"bar", "baz"}},
+				`[{"op": "add", "path": "/foo/1", "value": "qux"}, {"op": "add", "path": "/foo/0", "value": "a"}]`)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{"foo": []interface{}{"a", "bar", "qux", "baz"}}))
+		})
+
+		It("appends array items", func() {
+			res, err := apply([]interface{}{1},
+				`[{"op": "add", "path": "/-", "value": 2}, {"op": "add", "path": "/2", "value": 3}]`)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{1, 2, 3}))
+		})
+
+		It("replaces entire document", func() {
+			res, err := apply([]interface{}{1}, `[{"op": "add", "path": "", "value": {"a": 1}}]`)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{"a": 1}))
+		})
+
+		It("returns error if parent does not exist", func() {
+			_, err := apply(map[interface{}]interface{}{"foo": "bar"},
+				`[{"op": "add", "path": "/baz/bat", "value": "qux"}]`)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected to find a map key 'baz' for path '/baz' (found map keys: 'foo')"))
+		})
+
+		It("returns error if array index is out of bounds or invalid", func() {
+			_, err := apply([]interface{}{1}, `[{"op": "add", "path": "/2", "value": 2}]`)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected to find array index '2' but found array of length '1' for path '/2'"))
+
+			_, err = apply([]interface{}{1}, `[{"op": "add", "path": "/01", "value": 2}]`)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected to find array index at path '' but found '01'"))
+
+			_, err = apply([]interface{}{1}, `[{"op": "add", "path": "/-1", "value": 2}]`)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected to find array index at path '' but found '-1'"))
+		})
+	})
+
+	Describe("remove", func() {
+		It("removes map keys and array items", func() {
+			res, err := apply(map[interface{}]interface{}{"foo": []interface{}{1, 2, 3}, "bar": 1},
+				`[{"op": "remove", "path": "/foo/1"}, {"op": "remove", "path": "/bar"}]`)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{"foo": []interface{}{1, 3}}))
+		})
+
+		It("returns error if target does not exist", func() {
+			_, err := apply(map[interface{}]interface{}{"foo": []interface{}{1}},
+				`[{"op": "remove", "path": "/foo/-"}]`)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected to find array index at path '/foo' but found '-'"))
+		})
+	})
+
+	Describe("replace", func() {
+		It("replaces existing values", func() {
+			res, err := apply(map[interface{}]interface{}{"foo": []interface{}{1, 2}},
+				`[{"op": "replace", "path": "/foo/1", "value": 3}]`)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{"foo": []interface{}{1, 3}}))
+		})
+
+		It("returns error if target does not exist", func() {
+			_, err := apply(map[interface{}]interface{}{"foo": 1},
+				`[{"op": "replace", "path": "/bar", "value": 3}]`)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected to find a map key 'bar' for path '/bar' (found map keys: 'foo')"))
+
+			_, err = apply([]interface{}{1}, `[{"op": "replace", "path": "/1", "value": 3}]`)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected to find array index '1' but found array of length '1' for path '/1'"))
+		})
+	})
+
+	Describe("move", func() {
+		It("moves values between maps", func() {
+			res, err := apply(map[interface{}]interface{}{
+				"foo": map[interface{}]interface{}{"bar": "baz", "waldo": "fred"},
+				"qux": map[interface{}]interface{}{"corge": "grault"},
+			}, `[{"op": "move", "from": "/foo/waldo", "path": "/qux/thud"}]`)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{
+				"foo": map[interface{}]interface{}{"bar": "baz"},
+				"qux": map[interface{}]interface{}{"corge": "grault", "thud": "fred"},
+			}))
+		})
+
+		It("moves array item evaluating path after removal", func() {
+			res, err := apply([]interface{}{"all", "grass", "cows", "eat"},
+				`[{"op": "move", "from": "/1", "path": "/3"}]`)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{"all", "cows", "eat", "grass"}))
+		})
+
+		It("does nothing when from and path are the same", func() {
+			res, err := apply([]interface{}{1}, `[{"op": "move", "from": "/0", "path": "/0"}]`)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal([]interface{}{1}))
+		})
+
+		It("returns error when moving into its own child", func() {
+			_, err := apply(map[interface{}]interface{}{"a": map[interface{}]interface{}{}},
+				`[{"op": "move", "from": "/a", "path": "/a/b"}]`)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected path '/a/b' to not be a child of from path '/a'"))
+		})
+	})
+
+	Describe("copy", func() {
+		It("copies values", func() {
+			res, err := apply(map[interface{}]interface{}{"a": []interface{}{1}},
+				`[{"op": "copy", "from": "/a", "path": "/b"}, {"op": "add", "path": "/b/-", "value": 2}]`)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{"a": []interface{}{1}, "b": []interface{}{1, 2}}))
+		})
+	})
+
+	Describe("test", func() {
+		It("checks values", func() {
+			doc := map[interface{}]interface{}{"a": []interface{}{1, "b"}}
+
+			res, err := apply(doc, `[{"op": "test", "path": "/a", "value": [1, "b"]}]`)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(doc))
+
+			_, err = apply(doc, `[{"op": "test", "path": "/a/1", "value": "c"}]`)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Found value does not match expected value"))
+		})
+	})
+
+	It("treats go-patch specific syntax and numbers as plain map keys", func() {
+		doc := map[interface{}]interface{}{
+			"0":   "zero",
+			"a=b": "eq",
+			"c?":  "opt",
+			"*":   "star",
+			"d:e": "colon",
+			"~/":  "escaped",
+		}
+
+		res, err := apply(doc, `[
+  {"op": "replace", "path": "/0", "value": 0},
+  {"op": "replace", "path": "/a=b", "value": 1},
+  {"op": "replace", "path": "/c?", "value": 2},
+  {"op": "replace", "path": "/*", "value": 3},
+  {"op": "replace", "path": "/d:e", "value": 4},
+  {"op": "replace", "path": "/~0~1", "value": 5},
+  {"op": "add", "path": "/-", "value": 6}
+]`)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(map[interface{}]interface{}{
+			"0":   0,
+			"a=b": 1,
+			"c?":  2,
+			"*":   3,
+			"d:e": 4,
+			"~/":  5,
+			"-":   6,
+		}))
+	})
+
+	It("returns error when traversing through scalars", func() {
+		_, err := apply(map[interface{}]interface{}{"a": 1}, `[{"op": "add", "path": "/a/b", "value": 1}]`)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find a map or an array at path '/a' but found 'int'"))
+	})
+})
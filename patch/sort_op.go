@@ -0,0 +1,95 @@
+package patch
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SortOp sorts items of the array found at the path (or every array matched by it)
+// in place by their scalar values, by the value found at Key
+// or by the value found at By pointer relative to each item.
+// Values of different types are ordered null, booleans, numbers, strings;
+// items with equal values keep their relative order.
+type SortOp struct {
+	Path Pointer
+	Key  string
+	By   Pointer
+}
+
+func (op SortOp) Apply(doc interface{}) (interface{}, error) {
+	key := arrayItemKey{Key: op.Key, By: op.By}
+
+	return arrayUpdate{
+		Path:        op.Path,
+		KeepMissing: true,
+		Update: func(array []interface{}, path Pointer) ([]interface{}, error) {
+			vals := make([]interface{}, len(array))
+
+			for i, item := range array {
+				val, err := key.value(item, "sorted", path)
+				if err != nil {
+					return nil, err
+				}
+
+				if scalarRank(val) < 0 {
+					errMsg := "Expected to find scalar value to sort by in sorted item for path '%s' but found '%T'"
+					return nil, fmt.Errorf(errMsg, path, val)
+				}
+
+				vals[i] = val
+			}
+
+			idxs := make([]int, len(array))
+			for i := range idxs {
+				idxs[i] = i
+			}
+
+			sort.SliceStable(idxs, func(i, j int) bool {
+				return scalarLess(vals[idxs[i]], vals[idxs[j]])
+			})
+
+			sorted := make([]interface{}, len(array))
+			for i, idx := range idxs {
+				sorted[i] = array[idx]
+			}
+
+			return sorted, nil
+		},
+	}.Apply(doc)
+}
+
+// scalarRank orders types of scalar values (-1 if value is not a scalar)
+func scalarRank(val interface{}) int {
+	if _, ok := numberValue(val); ok {
+		return 2
+	}
+
+	switch val.(type) {
+	case nil:
+		return 0
+	case bool:
+		return 1
+	case string:
+		return 3
+	default:
+		return -1
+	}
+}
+
+func scalarLess(left, right interface{}) bool {
+	leftRank, rightRank := scalarRank(left), scalarRank(right)
+	if leftRank != rightRank {
+		return leftRank < rightRank
+	}
+
+	switch typedLeft := left.(type) {
+	case bool:
+		return !typedLeft && right.(bool)
+	case string:
+		return typedLeft < right.(string)
+	default:
+		leftNum, _ := numberValue(left)
+		rightNum, _ := numberValue(right)
+		return leftNum < rightNum
+	}
+}
@@ -0,0 +1,125 @@
+package patch_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v2"
+
+	. "github.com/stuart-pollock/go-patch/patch"
+)
+
+var _ = Describe("SortOp.Apply", func() {
+	fromYAML := func(str string) interface{} {
+		var val interface{}
+		err := yaml.Unmarshal([]byte(str), &val)
+		Expect(err).ToNot(HaveOccurred())
+		return val
+	}
+
+	It("sorts array of scalars", func() {
+		res, err := SortOp{Path: MustNewPointerFromString("/azs")}.Apply(fromYAML(`{azs: [z3, z1, z2]}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(fromYAML(`{azs: [z1, z2, z3]}`)))
+	})
+
+	It("sorts values of different types by their type and then by their value", func() {
+		res, err := SortOp{Path: MustNewPointerFromString("")}.Apply(fromYAML(`[b, 10, 2.5, true, null, a, -1, false]`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(fromYAML(`[null, false, true, -1, 2.5, 10, a, b]`)))
+	})
+
+	It("sorts array of maps by key keeping order of items with equal values", func() {
+		res, err := SortOp{Path: MustNewPointerFromString("/jobs"), Key: "name"}.Apply(fromYAML(`
+jobs:
+- {name: metrics, release: b}
+- {name: capi}
+- {name: metrics, release: a}
+`))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(fromYAML(`
+jobs:
+- {name: capi}
+- {name: metrics, release: b}
+- {name: metrics, release: a}
+`)))
+	})
+
+	It("sorts array of maps by nested key or by pointer", func() {
+		doc := fromYAML(`
+jobs:
+- {name: b, release: {name: r2}}
+- {name: a, release: {name: r1}}
+`)
+
+		expected := fromYAML(`
+jobs:
+- {name: a, release: {name: r1}}
+- {name: b, release: {name: r2}}
+`)
+
+		res, err := SortOp{Path: MustNewPointerFromString("/jobs"), Key: "release.name"}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(expected))
+
+		res, err = SortOp{Path: MustNewPointerFromString("/jobs"), By: MustNewPointerFromString("/release/name")}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(expected))
+	})
+
+	It("sorts every array matched by wildcard", func() {
+		res, err := SortOp{Path: MustNewPointerFromString("/instance_groups/*/jobs"), Key: "name"}.Apply(fromYAML(`
+instance_groups:
+- name: api
+  jobs: [{name: metrics}, {name: capi}]
+- name: worker
+  jobs: [{name: worker}, {name: syslog}]
+`))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(fromYAML(`
+instance_groups:
+- name: api
+  jobs: [{name: capi}, {name: metrics}]
+- name: worker
+  jobs: [{name: syslog}, {name: worker}]
+`)))
+	})
+
+	It("leaves missing and null arrays as is", func() {
+		res, err := SortOp{Path: MustNewPointerFromString("/jobs?")}.Apply(fromYAML(`{azs: [z1]}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(fromYAML(`{azs: [z1]}`)))
+
+		res, err = SortOp{Path: MustNewPointerFromString("/azs")}.Apply(fromYAML(`{azs: null}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(fromYAML(`{azs: null}`)))
+
+		res, err = SortOp{Path: MustNewPointerFromString("/instance_groups/*/azs?")}.Apply(fromYAML(`{instance_groups: [{azs: [z2, z1]}, {}]}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(fromYAML(`{instance_groups: [{azs: [z1, z2]}, {}]}`)))
+	})
+
+	It("returns an error if item does not have the key", func() {
+		_, err := SortOp{Path: MustNewPointerFromString("/jobs"), Key: "name"}.Apply(fromYAML(`{jobs: [{name: a}, {release: b}]}`))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find key 'name' in sorted item for path '/jobs'"))
+
+		_, err = SortOp{Path: MustNewPointerFromString("/jobs"), By: MustNewPointerFromString("/name")}.Apply(fromYAML(`{jobs: [{release: b}]}`))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find value at '/name' in sorted item for path '/jobs': " +
+			"Expected to find a map key 'name' for path '/name' (found map keys: 'release')"))
+	})
+
+	It("returns an error if value to sort by is not a scalar", func() {
+		_, err := SortOp{Path: MustNewPointerFromString("/jobs")}.Apply(fromYAML(`{jobs: [{name: a}]}`))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find scalar value to sort by in sorted item for path '/jobs' but found 'map[interface {}]interface {}'"))
+	})
+
+	It("returns an error if value at the path is not an array", func() {
+		_, err := SortOp{Path: MustNewPointerFromString("/jobs")}.Apply(fromYAML(`{jobs: a}`))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find an array at path '/jobs' but found 'string'"))
+	})
+})
@@ -0,0 +1,415 @@
+package patch
+
+import (
+	"fmt"
+)
+
+const (
+	// StrategicMergeDirectiveKey is a map key that changes how the map
+	// (or the array it's an item of) is merged
+	StrategicMergeDirectiveKey = "$patch"
+
+	StrategicMergeDelete  = "delete"  // removes the map key or matching array item
+	StrategicMergeReplace = "replace" // replaces the map, array item or entire array instead of merging
+)
+
+// StrategicMergeOp applies partial document onto the value found at the path
+// similarly to Kubernetes strategic merge patch: maps are deep merged as in MergeOp,
+// items of arrays that have a merge key are merged into existing items
+// with the same merge key value (other items are appended)
+// and all other arrays are treated as conflicting values.
+// Maps in the value may include '$patch: delete' or '$patch: replace' directives;
+// array item '{$patch: replace}' replaces the entire array with the rest of the items.
+type StrategicMergeOp struct {
+	Path      Pointer
+	Value     interface{} // will be cloned using yaml library
+	MergeKeys []MergeKey
+	Conflict  MergeConflict
+}
+
+// MergeKey identifies items of arrays found at Path by their Key.
+// Path is relative to the merged value and may include wildcards
+// (ex: '/instance_groups/*/jobs' with key 'name');
+// paths of different merge keys must not match the same array.
+type MergeKey struct {
+	Path Pointer
+	Key  string
+}
+
+func (op StrategicMergeOp) Apply(doc interface{}) (interface{}, error) {
+	err := MergeOp{Conflict: op.Conflict}.checkConflict()
+	if err != nil {
+		return nil, err
+	}
+
+	err = op.checkMergeKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	if isMultiMatch(op.Path) {
+		return op.applyAll(doc)
+	}
+
+	path := MergeOp{Path: op.Path}.optionalPath()
+
+	target, err := FindOp{Path: path}.Apply(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	merged, deleted, err := op.merge(target, op.Value, op.Path.Tokens(), []Token{RootToken{}})
+	if err != nil {
+		return nil, err
+	}
+
+	if deleted {
+		return RemoveOp{Path: op.Path}.Apply(doc)
+	}
+
+	return ReplaceOp{Path: path, Value: merged}.Apply(doc)
+}
+
+// applyAll merges value into every value matched by the path
+func (op StrategicMergeOp) applyAll(doc interface{}) (interface{}, error) {
+	foundVals, err := FindAllOp{Path: op.Path}.Resolve(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply to values starting from the end so that array indices stay valid
+	for i := len(foundVals) - 1; i >= 0; i-- {
+		foundVal := foundVals[i]
+
+		merged, deleted, err := op.merge(foundVal.Value, op.Value, foundVal.Path.Tokens(), []Token{RootToken{}})
+		if err != nil {
+			return nil, err
+		}
+
+		if deleted {
+			doc, err = RemoveOp{Path: foundVal.Path}.Apply(doc)
+		} else {
+			doc, err = ReplaceOp{Path: foundVal.Path, Value: merged}.Apply(doc)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return doc, nil
+}
+
+// merge returns value merged into target, or whether target should be deleted;
+// tokens refer to the target in the document, relTokens to the value within the merged value
+func (op StrategicMergeOp) merge(target, val interface{}, tokens, relTokens []Token) (interface{}, bool, error) {
+	switch typedVal := val.(type) {
+	case []interface{}:
+		mergeKey, found := op.mergeKey(relTokens)
+		typedTarget, ok := target.([]interface{})
+
+		if isReplaceArray(typedVal) {
+			merged, err := op.mergeArray(nil, typedVal, mergeKey, tokens, relTokens)
+			return merged, false, err
+		}
+
+		if found && (ok || target == nil) {
+			merged, err := op.mergeArray(typedTarget, typedVal, mergeKey, tokens, relTokens)
+			return merged, false, err
+		}
+
+		// Array without merge key conflicts with existing value
+		merged, err := op.mergeArray(nil, typedVal, mergeKey, tokens, relTokens)
+		if err != nil {
+			return nil, false, err
+		}
+
+		resolved, err := op.Conflict.resolve(target, merged, NewPointer(tokens))
+		return resolved, false, err
+
+	default:
+		typedMap, ok := newGenericMap(val)
+		if !ok {
+			resolved, err := op.Conflict.resolve(target, val, NewPointer(tokens))
+			return resolved, false, err
+		}
+
+		directive, err := op.directive(typedMap, tokens)
+		if err != nil {
+			return nil, false, err
+		}
+
+		switch directive {
+		case StrategicMergeDelete:
+			return nil, true, nil
+
+		case StrategicMergeReplace:
+			merged, err := op.mergeMap(nil, typedMap, tokens, relTokens)
+			return merged, false, err
+		}
+
+		if _, ok := newGenericMap(target); ok || target == nil {
+			merged, err := op.mergeMap(target, typedMap, tokens, relTokens)
+			return merged, false, err
+		}
+
+		// Map replacing other value is merged into nothing to apply its directives
+		merged, err := op.mergeMap(nil, typedMap, tokens, relTokens)
+		if err != nil {
+			return nil, false, err
+		}
+
+		resolved, err := op.Conflict.resolve(target, merged, NewPointer(tokens))
+		return resolved, false, err
+	}
+}
+
+func (op StrategicMergeOp) mergeMap(target interface{}, val genericMap, tokens, relTokens []Token) (interface{}, error) {
+	typedTarget, ok := newGenericMap(target)
+	if !ok {
+		typedTarget = val.Type().New()
+	}
+
+	result := typedTarget.Type().New()
+
+	// Shallow copy is enough since replace operation clones resulting value
+	for _, k := range typedTarget.Keys() {
+		v, _ := typedTarget.Get(k)
+		result.Set(k, v)
+	}
+
+	// Sorted keys make conflict errors deterministic
+	for _, k := range sortedKeys(val) {
+		if k == StrategicMergeDirectiveKey {
+			continue
+		}
+
+		v, _ := val.Get(k)
+		existing, _ := result.Get(k)
+		keyToken := newKeyToken(k)
+		keyTokens := append(append([]Token{}, tokens...), keyToken)
+		keyRelTokens := append(append([]Token{}, relTokens...), keyToken)
+
+		merged, deleted, err := op.merge(existing, v, keyTokens, keyRelTokens)
+		if err != nil {
+			return nil, err
+		}
+
+		if deleted {
+			result.Delete(k)
+		} else {
+			result.Set(k, merged)
+		}
+	}
+
+	return result.Obj(), nil
+}
+
+// mergeArray merges items into target array by merge key (or appends them if key is empty)
+func (op StrategicMergeOp) mergeArray(target, val []interface{}, mergeKey string, tokens, relTokens []Token) (interface{}, error) {
+	result := append([]interface{}{}, target...)
+
+	for _, item := range val {
+		idx := len(result)
+
+		if typedItem, ok := newGenericMap(item); ok {
+			if isReplaceArrayDirective(typedItem) {
+				continue
+			}
+
+			if len(mergeKey) > 0 {
+				idxs, err := op.matchingItems(result, typedItem, mergeKey, tokens)
+				if err != nil {
+					return nil, err
+				}
+
+				if len(idxs) > 1 {
+					return nil, OpMultipleMatchingIndexErr{NewPointer(tokens), idxs}
+				}
+
+				if len(idxs) == 1 {
+					idx = idxs[0]
+				}
+			}
+		} else if len(mergeKey) > 0 {
+			errMsg := "Expected to find a map with merge key '%s' in array at path '%s' but found '%T'"
+			return nil, fmt.Errorf(errMsg, mergeKey, NewPointer(tokens), item)
+		}
+
+		var existing interface{}
+		if idx < len(result) {
+			existing = result[idx]
+		}
+
+		itemToken := IndexToken{Index: idx}
+		itemTokens := append(append([]Token{}, tokens...), itemToken)
+		itemRelTokens := append(append([]Token{}, relTokens...), itemToken)
+
+		merged, deleted, err := op.merge(existing, item, itemTokens, itemRelTokens)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case deleted && idx < len(result):
+			result = append(result[:idx], result[idx+1:]...)
+		case deleted:
+			// Deleting missing item does not change the array
+		case idx < len(result):
+			result[idx] = merged
+		default:
+			result = append(result, merged)
+		}
+	}
+
+	return result, nil
+}
+
+// matchingItems returns indexes of items that have the same merge key value as given item
+func (op StrategicMergeOp) matchingItems(array []interface{}, item genericMap, mergeKey string, tokens []Token) ([]int, error) {
+	keyVal, found := nestedValue(item.Obj(), mergeKey)
+	if !found {
+		errMsg := "Expected to find merge key '%s' in array item at path '%s'"
+		return nil, fmt.Errorf(errMsg, mergeKey, NewPointer(tokens))
+	}
+
+	predVal, err := exactMatchingValue(keyVal)
+	if err != nil {
+		errMsg := "Expected to find scalar merge key '%s' value in array item at path '%s' but found '%T'"
+		return nil, fmt.Errorf(errMsg, mergeKey, NewPointer(tokens), keyVal)
+	}
+
+	return matchingIndexes(MatchingIndexToken{Key: mergeKey, Value: predVal}, array), nil
+}
+
+// mergeKey returns merge key of the first merge key path that matches the array
+func (op StrategicMergeOp) mergeKey(relTokens []Token) (string, bool) {
+	for _, mergeKey := range op.MergeKeys {
+		if matchesMergeKeyPath(mergeKey.Path.Tokens(), relTokens) {
+			return mergeKey.Key, true
+		}
+	}
+	return "", false
+}
+
+// checkMergeKeys makes sure that at most one merge key applies to any array
+func (op StrategicMergeOp) checkMergeKeys() error {
+	for i, mergeKey := range op.MergeKeys {
+		for _, otherMergeKey := range op.MergeKeys[i+1:] {
+			if mergeKeyPathsOverlap(mergeKey.Path.Tokens(), otherMergeKey.Path.Tokens()) {
+				errMsg := "Expected merge key paths '%s' and '%s' to not match the same array"
+				return fmt.Errorf(errMsg, mergeKey.Path, otherMergeKey.Path)
+			}
+		}
+	}
+	return nil
+}
+
+func (op StrategicMergeOp) directive(val genericMap, tokens []Token) (string, error) {
+	directive, found := val.Get(StrategicMergeDirectiveKey)
+	if !found {
+		return "", nil
+	}
+
+	switch directive {
+	case StrategicMergeDelete, StrategicMergeReplace:
+		return directive.(string), nil
+	default:
+		errMsg := "Expected to find one of the following merge directives: '%s' or '%s' but found '%v' at path '%s'"
+		return "", fmt.Errorf(errMsg, StrategicMergeDelete, StrategicMergeReplace, directive, NewPointer(tokens))
+	}
+}
+
+// isReplaceArray checks if array includes '{$patch: replace}' item
+func isReplaceArray(val []interface{}) bool {
+	for _, item := range val {
+		if typedItem, ok := newGenericMap(item); ok && isReplaceArrayDirective(typedItem) {
+			return true
+		}
+	}
+	return false
+}
+
+// isReplaceArrayDirective checks if map only consists of replace directive
+func isReplaceArrayDirective(item genericMap) bool {
+	directive, found := item.Get(StrategicMergeDirectiveKey)
+	return found && item.Len() == 1 && directive == StrategicMergeReplace
+}
+
+// mergeKeyPathsOverlap checks if there is a path that both merge key paths match
+func mergeKeyPathsOverlap(leftTokens, rightTokens []Token) bool {
+	if len(leftTokens) != len(rightTokens) {
+		return false
+	}
+
+	for i, leftToken := range leftTokens {
+		if !mergeKeyTokensOverlap(leftToken, rightTokens[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// mergeKeyTokensOverlap checks if there is a map key or array index
+// that both merge key path tokens match regardless of their order
+func mergeKeyTokensOverlap(leftToken, rightToken Token) bool {
+	// Patterns are checked against more specific tokens
+	switch rightToken.(type) {
+	case WildcardToken:
+		leftToken, rightToken = rightToken, leftToken
+	case KeyGlobToken:
+		if _, ok := leftToken.(WildcardToken); !ok {
+			leftToken, rightToken = rightToken, leftToken
+		}
+	}
+
+	switch typedLeftToken := leftToken.(type) {
+	case WildcardToken:
+		return true
+
+	case KeyGlobToken:
+		if typedRightToken, ok := rightToken.(KeyGlobToken); ok {
+			return globsOverlap(typedLeftToken.Pattern, typedRightToken.Pattern)
+		}
+	}
+
+	return matchesMergeKeyPath([]Token{leftToken}, []Token{rightToken})
+}
+
+// matchesMergeKeyPath checks if concrete tokens match merge key path
+// where wildcards match any map key or array index
+func matchesMergeKeyPath(pathTokens, tokens []Token) bool {
+	if len(pathTokens) != len(tokens) {
+		return false
+	}
+
+	for i, pathToken := range pathTokens {
+		switch typedPathToken := pathToken.(type) {
+		case RootToken, WildcardToken:
+			continue
+
+		case KeyToken:
+			typedToken, ok := tokens[i].(KeyToken)
+			if !ok || typedToken.mapKey() != typedPathToken.mapKey() {
+				return false
+			}
+
+		case KeyGlobToken:
+			typedToken, ok := tokens[i].(KeyToken)
+			if !ok || len(typedToken.Tag) > 0 || !globMatch(typedPathToken.Pattern, typedToken.Key) {
+				return false
+			}
+
+		case IndexToken:
+			typedToken, ok := tokens[i].(IndexToken)
+			if !ok || typedToken.Index != typedPathToken.Index {
+				return false
+			}
+
+		default:
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,324 @@
+package patch_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v2"
+
+	. "github.com/stuart-pollock/go-patch/patch"
+)
+
+var _ = Describe("StrategicMergeOp.Apply", func() {
+	fromYAML := func(str string) interface{} {
+		var val interface{}
+		err := yaml.Unmarshal([]byte(str), &val)
+		Expect(err).ToNot(HaveOccurred())
+		return val
+	}
+
+	var (
+		doc       interface{}
+		mergeKeys []MergeKey
+	)
+
+	BeforeEach(func() {
+		doc = fromYAML(`
+instance_groups:
+- name: api
+  instances: 1
+  networks: [{name: default}]
+  jobs:
+  - name: capi
+    properties: {port: 80}
+  - name: metrics
+- name: worker
+  instances: 2
+  jobs:
+  - name: worker
+`)
+
+		mergeKeys = []MergeKey{
+			{Path: MustNewPointerFromString("/instance_groups"), Key: "name"},
+			{Path: MustNewPointerFromString("/instance_groups/*/jobs"), Key: "name"},
+			{Path: MustNewPointerFromString("/instance_groups/*/networks"), Key: "name"},
+		}
+	})
+
+	It("merges array items by merge key and appends new ones", func() {
+		res, err := StrategicMergeOp{
+			Path: MustNewPointerFromString(""),
+			Value: fromYAML(`
+instance_groups:
+- name: worker
+  instances: 3
+  jobs:
+  - name: worker
+    properties: {queue: default}
+  - name: metrics
+- name: api
+  jobs:
+  - name: capi
+    properties: {tls: true}
+- name: db
+  instances: 1
+`),
+			MergeKeys: mergeKeys,
+		}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(fromYAML(`
+instance_groups:
+- name: api
+  instances: 1
+  networks: [{name: default}]
+  jobs:
+  - name: capi
+    properties: {port: 80, tls: true}
+  - name: metrics
+- name: worker
+  instances: 3
+  jobs:
+  - name: worker
+    properties: {queue: default}
+  - name: metrics
+- name: db
+  instances: 1
+`)))
+	})
+
+	It("replaces arrays without merge keys", func() {
+		res, err := StrategicMergeOp{
+			Path:      MustNewPointerFromString("/instance_groups/name=api"),
+			Value:     fromYAML(`{networks: [{name: private}], azs: [z1]}`),
+			MergeKeys: []MergeKey{},
+		}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(FindOp{Path: MustNewPointerFromString("/instance_groups/name=api/networks")}.Apply(res)).To(Equal(fromYAML(`[{name: private}]`)))
+		Expect(FindOp{Path: MustNewPointerFromString("/instance_groups/name=api/azs")}.Apply(res)).To(Equal(fromYAML(`[z1]`)))
+	})
+
+	It("uses merge keys relative to the path", func() {
+		res, err := StrategicMergeOp{
+			Path:      MustNewPointerFromString("/instance_groups/name=api/jobs"),
+			Value:     fromYAML(`[{name: capi, release: capi}]`),
+			MergeKeys: []MergeKey{{Path: MustNewPointerFromString(""), Key: "name"}},
+		}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(FindOp{Path: MustNewPointerFromString("/instance_groups/name=api/jobs")}.Apply(res)).To(Equal(fromYAML(`
+- name: capi
+  release: capi
+  properties: {port: 80}
+- name: metrics
+`)))
+	})
+
+	It("matches items by merge key value of the same type", func() {
+		res, err := StrategicMergeOp{
+			Path:      MustNewPointerFromString("/ports"),
+			Value:     fromYAML(`[{port: 80, protocol: udp}, {port: "443", protocol: tcp}]`),
+			MergeKeys: []MergeKey{{Path: MustNewPointerFromString(""), Key: "port"}},
+		}.Apply(fromYAML(`{ports: [{port: 80}, {port: 443}]}`))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(fromYAML(`{ports: [{port: 80, protocol: udp}, {port: 443}, {port: "443", protocol: tcp}]}`)))
+	})
+
+	It("deletes matching array items and map keys", func() {
+		res, err := StrategicMergeOp{
+			Path: MustNewPointerFromString(""),
+			Value: fromYAML(`
+instance_groups:
+- name: api
+  networks: {$patch: delete}
+  jobs:
+  - {name: metrics, $patch: delete}
+  - {name: missing, $patch: delete}
+- {name: worker, $patch: delete}
+`),
+			MergeKeys: mergeKeys,
+		}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(fromYAML(`
+instance_groups:
+- name: api
+  instances: 1
+  jobs:
+  - name: capi
+    properties: {port: 80}
+`)))
+	})
+
+	It("replaces maps, array items and arrays wholesale", func() {
+		res, err := StrategicMergeOp{
+			Path: MustNewPointerFromString(""),
+			Value: fromYAML(`
+instance_groups:
+- name: api
+  jobs:
+  - {name: capi, $patch: replace}
+- name: worker
+  jobs:
+  - $patch: replace
+  - name: new-worker
+    properties: {$patch: replace, queue: default}
+`),
+			MergeKeys: mergeKeys,
+		}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(fromYAML(`
+instance_groups:
+- name: api
+  instances: 1
+  networks: [{name: default}]
+  jobs:
+  - name: capi
+  - name: metrics
+- name: worker
+  instances: 2
+  jobs:
+  - name: new-worker
+    properties: {queue: default}
+`)))
+	})
+
+	It("resolves conflicts with conflict policy", func() {
+		_, err := StrategicMergeOp{
+			Path:      MustNewPointerFromString(""),
+			Value:     fromYAML(`{instance_groups: [{name: api, instances: 5}]}`),
+			MergeKeys: mergeKeys,
+			Conflict:  MergeConflictError,
+		}.Apply(doc)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find no conflicting value at path '/instance_groups/0/instances' but found '1' (merged value '5')"))
+
+		res, err := StrategicMergeOp{
+			Path:      MustNewPointerFromString(""),
+			Value:     fromYAML(`{instance_groups: [{name: api, instances: 5, azs: [z1]}]}`),
+			MergeKeys: mergeKeys,
+			Conflict:  MergeConflictKeep,
+		}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(FindOp{Path: MustNewPointerFromString("/instance_groups/name=api/instances")}.Apply(res)).To(Equal(1))
+		Expect(FindOp{Path: MustNewPointerFromString("/instance_groups/name=api/azs")}.Apply(res)).To(Equal(fromYAML(`[z1]`)))
+	})
+
+	It("creates missing parents", func() {
+		res, err := StrategicMergeOp{
+			Path:      MustNewPointerFromString("/instance_groups/name=worker/jobs/name=worker/properties"),
+			Value:     fromYAML(`{queues: [{name: default}, {name: default, size: 1, $patch: replace}]}`),
+			MergeKeys: []MergeKey{{Path: MustNewPointerFromString("/queues"), Key: "name"}},
+		}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(FindOp{Path: MustNewPointerFromString("/instance_groups/name=worker/jobs/name=worker/properties")}.Apply(res)).To(Equal(fromYAML(`
+queues: [{name: default, size: 1}]
+`)))
+	})
+
+	It("returns an error if array item does not have merge key", func() {
+		_, err := StrategicMergeOp{
+			Path:      MustNewPointerFromString(""),
+			Value:     fromYAML(`{instance_groups: [{instances: 5}]}`),
+			MergeKeys: mergeKeys,
+		}.Apply(doc)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find merge key 'name' in array item at path '/instance_groups'"))
+
+		_, err = StrategicMergeOp{
+			Path:      MustNewPointerFromString(""),
+			Value:     fromYAML(`{instance_groups: [api]}`),
+			MergeKeys: mergeKeys,
+		}.Apply(doc)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find a map with merge key 'name' in array at path '/instance_groups' but found 'string'"))
+	})
+
+	It("returns an error if multiple array items have the same merge key value", func() {
+		_, err := StrategicMergeOp{
+			Path:      MustNewPointerFromString(""),
+			Value:     fromYAML(`{items: [{name: a, value: 1}]}`),
+			MergeKeys: []MergeKey{{Path: MustNewPointerFromString("/items"), Key: "name"}},
+		}.Apply(fromYAML(`{items: [{name: a}, {name: a}]}`))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find exactly one matching array item for path '/items' but found 2"))
+	})
+
+	It("returns an error if glob pattern and wildcard merge key paths match the same array in any order", func() {
+		for _, paths := range [][]string{{"/a/x*/jobs", "/a/*/jobs"}, {"/a/*/jobs", "/a/x*/jobs"}} {
+			_, err := StrategicMergeOp{
+				Path:  MustNewPointerFromString(""),
+				Value: fromYAML(`{a: {}}`),
+				MergeKeys: []MergeKey{
+					{Path: MustNewPointerFromString(paths[0]), Key: "name"},
+					{Path: MustNewPointerFromString(paths[1]), Key: "id"},
+				},
+			}.Apply(fromYAML(`{a: {}}`))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(fmt.Sprintf(
+				"Expected merge key paths '%s' and '%s' to not match the same array", paths[0], paths[1])))
+		}
+
+		for _, paths := range [][]string{{"/a/x*/jobs", "/a/0/jobs"}, {"/a/0/jobs", "/a/x*/jobs"}} {
+			_, err := StrategicMergeOp{
+				Path:  MustNewPointerFromString(""),
+				Value: fromYAML(`{a: {}}`),
+				MergeKeys: []MergeKey{
+					{Path: MustNewPointerFromString(paths[0]), Key: "name"},
+					{Path: MustNewPointerFromString(paths[1]), Key: "id"},
+				},
+			}.Apply(fromYAML(`{a: {}}`))
+			Expect(err).ToNot(HaveOccurred())
+		}
+	})
+
+	It("returns an error if merge key paths match the same array", func() {
+		_, err := StrategicMergeOp{
+			Path:  MustNewPointerFromString(""),
+			Value: fromYAML(`{items: [{name: a}]}`),
+			MergeKeys: []MergeKey{
+				{Path: MustNewPointerFromString("/items"), Key: "name"},
+				{Path: MustNewPointerFromString("/it*"), Key: "id"},
+			},
+		}.Apply(fromYAML(`{items: []}`))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected merge key paths '/items' and '/it*' to not match the same array"))
+
+		_, err = StrategicMergeOp{
+			Path:  MustNewPointerFromString(""),
+			Value: fromYAML(`{items: [{name: a}]}`),
+			MergeKeys: []MergeKey{
+				{Path: MustNewPointerFromString("/a*/jobs"), Key: "name"},
+				{Path: MustNewPointerFromString("/*b/jobs"), Key: "id"},
+			},
+		}.Apply(fromYAML(`{items: []}`))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected merge key paths '/a*/jobs' and '/*b/jobs' to not match the same array"))
+
+		_, err = StrategicMergeOp{
+			Path:  MustNewPointerFromString(""),
+			Value: fromYAML(`{items: [{name: a}]}`),
+			MergeKeys: []MergeKey{
+				{Path: MustNewPointerFromString("/a*/jobs"), Key: "name"},
+				{Path: MustNewPointerFromString("/b*/jobs"), Key: "id"},
+				{Path: MustNewPointerFromString("/items"), Key: "name"},
+			},
+		}.Apply(fromYAML(`{items: []}`))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("returns an error if directive is unknown", func() {
+		_, err := StrategicMergeOp{
+			Path:  MustNewPointerFromString(""),
+			Value: fromYAML(`{a: {$patch: retain}}`),
+		}.Apply(fromYAML(`{a: {}}`))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find one of the following merge directives: 'delete' or 'replace' but found 'retain' at path '/a'"))
+	})
+})
@@ -0,0 +1,81 @@
+package patch
+
+import (
+	"fmt"
+)
+
+// SwapOp exchanges values found at from path and path.
+// Both paths must resolve to exactly one value
+// and neither value can be nested in the other one.
+type SwapOp struct {
+	Path Pointer
+	From Pointer
+}
+
+func (op SwapOp) Apply(doc interface{}) (interface{}, error) {
+	from, to, err := op.resolve(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	err = setFoundValue(doc, from.Path, to.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	err = setFoundValue(doc, to.Path, from.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// resolve returns values to exchange with their concrete paths
+func (op SwapOp) resolve(doc interface{}) (FoundValue, FoundValue, error) {
+	from, err := op.find(doc, op.From)
+	if err != nil {
+		return FoundValue{}, FoundValue{}, err
+	}
+
+	to, err := op.find(doc, op.Path)
+	if err != nil {
+		return FoundValue{}, FoundValue{}, err
+	}
+
+	// Swapping value with itself does not change the document
+	if from.Path.String() != to.Path.String() {
+		if isWithinPath(to.Path, from.Path) || isWithinPath(from.Path, to.Path) {
+			errMsg := "Expected paths '%s' and '%s' to not be nested in each other"
+			return FoundValue{}, FoundValue{}, fmt.Errorf(errMsg, op.From, op.Path)
+		}
+	}
+
+	return from, to, nil
+}
+
+func (op SwapOp) find(doc interface{}, path Pointer) (FoundValue, error) {
+	if len(path.Tokens()) == 1 {
+		return FoundValue{}, fmt.Errorf("Cannot swap entire document")
+	}
+
+	if isRangePath(path) {
+		return FoundValue{}, fmt.Errorf("Expected not to find range token in path '%s' (not supported in swap operations)", path)
+	}
+
+	foundVals, err := FindAllOp{Path: path}.Resolve(doc)
+	if err != nil {
+		return FoundValue{}, err
+	}
+
+	if len(foundVals) != 1 {
+		return FoundValue{}, fmt.Errorf("Expected to find exactly one value for path '%s' but found %d", path, len(foundVals))
+	}
+
+	// Recursive descent token may resolve to the document itself
+	if len(foundVals[0].Path.Tokens()) == 1 {
+		return FoundValue{}, fmt.Errorf("Cannot swap entire document")
+	}
+
+	return foundVals[0], nil
+}
@@ -0,0 +1,126 @@
+package patch_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/stuart-pollock/go-patch/patch"
+)
+
+var _ = Describe("SwapOp.Apply", func() {
+	It("swaps array items", func() {
+		doc := map[interface{}]interface{}{
+			"items": []interface{}{"a", "b", "c"},
+		}
+
+		res, err := SwapOp{Path: MustNewPointerFromString("/items/0"), From: MustNewPointerFromString("/items/2")}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(map[interface{}]interface{}{
+			"items": []interface{}{"c", "b", "a"},
+		}))
+	})
+
+	It("swaps matching array items", func() {
+		doc := map[interface{}]interface{}{
+			"instance_groups": []interface{}{
+				map[interface{}]interface{}{"name": "db"},
+				map[interface{}]interface{}{"name": "api"},
+				map[interface{}]interface{}{"name": "worker"},
+			},
+		}
+
+		res, err := SwapOp{
+			Path: MustNewPointerFromString("/instance_groups/name=api:prev"),
+			From: MustNewPointerFromString("/instance_groups/name=worker"),
+		}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(map[interface{}]interface{}{
+			"instance_groups": []interface{}{
+				map[interface{}]interface{}{"name": "worker"},
+				map[interface{}]interface{}{"name": "api"},
+				map[interface{}]interface{}{"name": "db"},
+			},
+		}))
+	})
+
+	It("swaps values in different maps and arrays", func() {
+		doc := map[interface{}]interface{}{
+			"abc": map[interface{}]interface{}{"def": "def"},
+			"xyz": []interface{}{"xyz"},
+		}
+
+		res, err := SwapOp{Path: MustNewPointerFromString("/xyz/0"), From: MustNewPointerFromString("/abc/def")}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(map[interface{}]interface{}{
+			"abc": map[interface{}]interface{}{"def": "xyz"},
+			"xyz": []interface{}{"def"},
+		}))
+	})
+
+	It("leaves document unchanged if both paths refer to the same value", func() {
+		doc := map[interface{}]interface{}{
+			"items": []interface{}{"a", "b"},
+		}
+
+		res, err := SwapOp{Path: MustNewPointerFromString("/items/1"), From: MustNewPointerFromString("/items/-1")}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(map[interface{}]interface{}{
+			"items": []interface{}{"a", "b"},
+		}))
+	})
+
+	It("returns an error if value is not found", func() {
+		doc := map[interface{}]interface{}{
+			"items": []interface{}{"a", "b"},
+		}
+
+		_, err := SwapOp{Path: MustNewPointerFromString("/items/2"), From: MustNewPointerFromString("/items/0")}.Apply(doc)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find array index '2' but found array of length '2' for path '/items/2'"))
+
+		_, err = SwapOp{Path: MustNewPointerFromString("/items/0"), From: MustNewPointerFromString("/missing?")}.Apply(doc)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find exactly one value for path '/missing?' but found 0"))
+	})
+
+	It("returns an error if path matches multiple values", func() {
+		doc := map[interface{}]interface{}{
+			"items": []interface{}{"a", "b"},
+		}
+
+		_, err := SwapOp{Path: MustNewPointerFromString("/items/*"), From: MustNewPointerFromString("/items/0")}.Apply(doc)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find exactly one value for path '/items/*' but found 2"))
+
+		_, err = SwapOp{Path: MustNewPointerFromString("/items/0..1"), From: MustNewPointerFromString("/items/1")}.Apply(doc)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected not to find range token in path '/items/..1' (not supported in swap operations)"))
+	})
+
+	It("returns an error if values are nested in each other", func() {
+		doc := map[interface{}]interface{}{
+			"items": []interface{}{
+				map[interface{}]interface{}{"name": "a"},
+			},
+		}
+
+		_, err := SwapOp{Path: MustNewPointerFromString("/items/name=a/name"), From: MustNewPointerFromString("/items/0")}.Apply(doc)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected paths '/items/0' and '/items/name=a/name' to not be nested in each other"))
+	})
+
+	It("returns an error if path is for the entire document", func() {
+		_, err := SwapOp{Path: MustNewPointerFromString(""), From: MustNewPointerFromString("/a")}.Apply(map[interface{}]interface{}{"a": 1})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Cannot swap entire document"))
+	})
+	It("returns an error if path resolves to the entire document", func() {
+		_, err := SwapOp{Path: MustNewPointerFromString("/**"), From: MustNewPointerFromString("/**")}.Apply("a")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Cannot swap entire document"))
+	})
+})
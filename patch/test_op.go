@@ -2,7 +2,6 @@ package patch
 
 import (
 	"fmt"
-	"reflect"
 )
 
 type TestOp struct {
@@ -12,24 +11,92 @@ type TestOp struct {
 }
 
 func (op TestOp) Apply(doc interface{}) (interface{}, error) {
+	if isMultiMatch(op.Path) {
+		if op.Absent {
+			return op.checkAllAbsence(doc)
+		}
+		return op.checkAllValues(doc)
+	}
+
 	if op.Absent {
 		return op.checkAbsence(doc)
 	}
 	return op.checkValue(doc)
 }
 
-func (op TestOp) checkAbsence(doc interface{}) (interface{}, error) {
-	_, err := FindOp{Path: op.Path}.Apply(doc)
+// checkAllAbsence checks that last token does not match anything
+// within every value matched by the rest of the path
+// (or that path does not match anything if it's recursive)
+func (op TestOp) checkAllAbsence(doc interface{}) (interface{}, error) {
+	tokens := op.Path.Tokens()
+	lastToken := tokens[len(tokens)-1]
+
+	if isMultiMatch(NewPointer([]Token{RootToken{}, lastToken})) || isRecursive(op.Path) {
+		parents, err := FindAllOp{Path: op.Path}.resolve(doc)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(parents) > 0 {
+			return nil, fmt.Errorf("Expected to not find '%s' (found '%s')", op.Path, parents[0].Path)
+		}
+
+		return doc, nil
+	}
+
+	parents, err := FindAllOp{Path: NewPointer(tokens[:len(tokens)-1])}.Resolve(doc)
 	if err != nil {
-		if typedErr, ok := err.(OpMissingIndexErr); ok {
-			if typedErr.Path.String() == op.Path.String() {
-				return doc, nil
+		return nil, err
+	}
+
+	for _, parent := range parents {
+		// Look up last token relative to the parent value
+		relPath := NewPointer([]Token{RootToken{}, lastToken})
+
+		_, err := FindOp{Path: relPath}.Apply(parent.Value)
+		if err != nil {
+			if op.isAbsentErr(err, relPath) {
+				continue
 			}
+			return nil, err
 		}
-		if typedErr, ok := err.(OpMissingMapKeyErr); ok {
-			if typedErr.Path.String() == op.Path.String() {
-				return doc, nil
-			}
+
+		path := NewPointer(append(parent.Path.Tokens(), lastToken))
+
+		return nil, fmt.Errorf("Expected to not find '%s' (found '%s')", op.Path, path)
+	}
+
+	return doc, nil
+}
+
+// checkAllValues checks that there is at least one matched value
+// and that every matched value equals to the expected value
+func (op TestOp) checkAllValues(doc interface{}) (interface{}, error) {
+	foundVals, err := FindAllOp{Path: op.Path}.Resolve(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(foundVals) == 0 {
+		return nil, fmt.Errorf("Expected to find at least one value for path '%s'", op.Path)
+	}
+
+	for _, foundVal := range foundVals {
+		if !valuesEqual(foundVal.Value, op.Value) {
+			return nil, fmt.Errorf("Found value at path '%s' does not match expected value", foundVal.Path)
+		}
+	}
+
+	return doc, nil
+}
+
+// checkAbsence checks that path does not match anything;
+// path is also absent if any of its optional matching index tokens does not match anything
+func (op TestOp) checkAbsence(doc interface{}) (interface{}, error) {
+	_, err := FindOp{Path: op.existingPath()}.Apply(doc)
+	if err != nil {
+		if op.isAbsentErr(err, op.Path) || op.isMissingOptionalErr(err) {
+			return doc, nil
 		}
 		return nil, err
 	}
@@ -37,13 +104,64 @@ func (op TestOp) checkAbsence(doc interface{}) (interface{}, error) {
 	return nil, fmt.Errorf("Expected to not find '%s'", op.Path)
 }
 
+func (TestOp) isAbsentErr(err error, path Pointer) bool {
+	if typedErr, ok := err.(OpMissingIndexErr); ok {
+		return typedErr.Path.String() == path.String()
+	}
+	if typedErr, ok := err.(OpMissingMapKeyErr); ok {
+		return typedErr.Path.String() == path.String()
+	}
+	return false
+}
+
+// isMissingOptionalErr checks if error is caused by an optional matching index token not matching anything
+func (op TestOp) isMissingOptionalErr(err error) bool {
+	typedErr, ok := err.(OpMultipleMatchingIndexErr)
+	if !ok || len(typedErr.Idxs) > 0 {
+		return false
+	}
+
+	errTokens := typedErr.Path.Tokens()
+	tokens := op.Path.Tokens()
+
+	if len(errTokens) > len(tokens) {
+		return false
+	}
+
+	token, ok := tokens[len(errTokens)-1].(MatchingIndexToken)
+
+	return ok && token.Optional
+}
+
+// existingPath returns path with optional matching index tokens that only match
+// existing array items (instead of items that would be added by ReplaceOp)
+func (op TestOp) existingPath() Pointer {
+	var tokens []Token
+
+	for _, token := range op.Path.Tokens() {
+		if typedToken, ok := token.(MatchingIndexToken); ok {
+			typedToken.Optional = false
+			token = typedToken
+		}
+		tokens = append(tokens, token)
+	}
+
+	return NewPointer(tokens)
+}
+
 func (op TestOp) checkValue(doc interface{}) (interface{}, error) {
-	foundVal, err := FindOp{Path: op.Path}.Apply(doc)
+	foundVal, err := FindOp{Path: op.existingPath()}.Apply(doc)
 	if err != nil {
+		if op.isMissingOptionalErr(err) {
+			// Report path as it was given
+			typedErr := err.(OpMultipleMatchingIndexErr)
+			typedErr.Path = NewPointer(op.Path.Tokens()[:len(typedErr.Path.Tokens())])
+			return nil, typedErr
+		}
 		return nil, err
 	}
 
-	if !reflect.DeepEqual(foundVal, op.Value) {
+	if !valuesEqual(foundVal, op.Value) {
 		return nil, fmt.Errorf("Found value does not match expected value")
 	}
 
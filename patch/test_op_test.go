@@ -101,4 +101,138 @@ var _ = Describe("TestOp.Apply", func() {
 			Expect(err.Error()).To(Equal("Expected to not find '/a'"))
 		})
 	})
+
+	Describe("optional matching index token", func() {
+		doc := []interface{}{
+			map[interface{}]interface{}{"name": "foo", "release": "foo"},
+			map[interface{}]interface{}{"name": "bar"},
+		}
+
+		It("checks existing array items", func() {
+			res, err := TestOp{
+				Path:  MustNewPointerFromString("/name=foo?:before=name=bar/release"),
+				Value: "foo",
+			}.Apply(doc)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(doc))
+
+			_, err = TestOp{
+				Path:  MustNewPointerFromString("/name=baz?:after=name=bar"),
+				Value: map[interface{}]interface{}{"name": "baz"},
+			}.Apply(doc)
+
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(
+				"Expected to find exactly one matching array item for path '/name=baz?:after=name=bar' but found 0"))
+		})
+
+		It("treats path as absent if optional item does not exist", func() {
+			res, err := TestOp{
+				Path:   MustNewPointerFromString("/name=baz?:after=name=bar/release"),
+				Absent: true,
+			}.Apply(doc)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(doc))
+
+			_, err = TestOp{
+				Path:   MustNewPointerFromString("/name=foo?:before=name=bar"),
+				Absent: true,
+			}.Apply(doc)
+
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected to not find '/name=foo?:before=name=bar'"))
+		})
+	})
+
+	Describe("recursive descent", func() {
+		doc := map[interface{}]interface{}{
+			"instance_groups": []interface{}{
+				map[interface{}]interface{}{"name": "api", "tls": map[interface{}]interface{}{"enabled": true}},
+				map[interface{}]interface{}{"name": "worker", "jobs": []interface{}{
+					map[interface{}]interface{}{"tls": map[interface{}]interface{}{"enabled": true}},
+				}},
+			},
+		}
+
+		It("checks that every matched value equals expected value", func() {
+			res, err := TestOp{Path: MustNewPointerFromString("/**/tls/enabled"), Value: true}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(doc))
+
+			_, err = TestOp{Path: MustNewPointerFromString("/**/tls/enabled"), Value: false}.Apply(doc)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Found value at path '/instance_groups/0/tls/enabled' does not match expected value"))
+		})
+
+		It("checks that nothing is matched", func() {
+			res, err := TestOp{Path: MustNewPointerFromString("/**/password"), Absent: true}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(doc))
+
+			_, err = TestOp{Path: MustNewPointerFromString("/**/tls"), Absent: true}.Apply(doc)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected to not find '/**/tls' (found '/instance_groups/0/tls')"))
+		})
+	})
+
+	Describe("wildcard", func() {
+		doc := map[interface{}]interface{}{
+			"instance_groups": []interface{}{
+				map[interface{}]interface{}{"name": "api", "instances": 1},
+				map[interface{}]interface{}{"name": "worker", "instances": 1, "env": "env"},
+			},
+		}
+
+		It("checks that every matched value equals expected value", func() {
+			res, err := TestOp{Path: MustNewPointerFromString("/instance_groups/*/instances"), Value: 1}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(doc))
+
+			_, err = TestOp{Path: MustNewPointerFromString("/instance_groups/*/name"), Value: "api"}.Apply(doc)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Found value at path '/instance_groups/1/name' does not match expected value"))
+		})
+
+		It("returns an error if nothing matched", func() {
+			_, err := TestOp{Path: MustNewPointerFromString("/instance_groups/*/vm?"), Value: 1}.Apply(doc)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected to find at least one value for path '/instance_groups/*/vm?'"))
+		})
+
+		It("checks that none of the matched values contain the last token", func() {
+			res, err := TestOp{Path: MustNewPointerFromString("/instance_groups/*/vm"), Absent: true}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(doc))
+
+			_, err = TestOp{Path: MustNewPointerFromString("/instance_groups/*/env"), Absent: true}.Apply(doc)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected to not find '/instance_groups/*/env' (found '/instance_groups/1/env')"))
+		})
+
+		It("checks every array item selected by 'all' modifier", func() {
+			res, err := TestOp{Path: MustNewPointerFromString("/instance_groups/name=worker:all/env"), Value: "env"}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(doc))
+
+			_, err = TestOp{Path: MustNewPointerFromString("/instance_groups/name=worker:all/env"), Value: "other"}.Apply(doc)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Found value at path '/instance_groups/1/env' does not match expected value"))
+
+			res, err = TestOp{Path: MustNewPointerFromString("/instance_groups/name=db:all"), Absent: true}.Apply(doc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(doc))
+		})
+
+		It("checks that wildcard does not match anything", func() {
+			res, err := TestOp{Path: MustNewPointerFromString("/empty/*"), Absent: true}.Apply(map[interface{}]interface{}{"empty": []interface{}{}})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(map[interface{}]interface{}{"empty": []interface{}{}}))
+
+			_, err = TestOp{Path: MustNewPointerFromString("/instance_groups/*"), Absent: true}.Apply(doc)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected to not find '/instance_groups/*' (found '/instance_groups/0')"))
+		})
+	})
 })
@@ -13,18 +13,60 @@ type IndexToken struct {
 
 type WildcardToken struct{}
 
+// RangeToken refers to array items from Start (inclusive) to End (exclusive)
+// or to the end of the array if ToEnd is set (ex: 2..5, ..3, -2..);
+// negative positions count from the end of the array
+type RangeToken struct {
+	Start int
+	End   int
+	ToEnd bool
+}
+
 type AfterLastIndexToken struct{}
 
+// MatchingIndexToken matches array items that are maps
+// with Key satisfying Operator with Value and that satisfy all additional Predicates
+// (ex: name=api,az=z1). Keys may refer to nested values (ex: release.name);
+// empty key refers to array items that are not maps (ex: =z1 matches "z1").
+// Values are compared as strings and as YAML would parse them (ex: 0 matches integer 0);
+// YAML tags force specific type (ex: !!str 0). Keys that contain '<' or '>',
+// or end with '!', '~', '^' or '$', must be quoted (ex: 'a!'=b).
 type MatchingIndexToken struct {
-	Key       string
-	Value     string
-	Optional  bool
-	Modifiers []Modifier
+	Key        string
+	Value      string
+	Operator   string // empty for equality
+	Predicates []MatchingPredicate
+	Optional   bool
+	Modifiers  []Modifier
 }
 
+// MatchingPredicate checks value found at Key using Operator: equality (empty operator),
+// '!=', '~=' (regular expression), '^=' (prefix), '$=' (suffix) or numeric comparisons
+// ('<', '<=', '>', '>='). Equality with '*' checks that key is present ('!=' that it's absent).
+type MatchingPredicate struct {
+	Key      string
+	Value    string
+	Operator string
+}
+
+// KeyToken refers to a map key; keys with a YAML tag (ex: '!!int', '!!bool')
+// refer to non-string map keys that YAML would parse Key to (ex: 80, true)
 type KeyToken struct {
 	Key      string
 	Optional bool
+	Tag      string
+}
+
+// KeyGlobToken matches all map keys that match the pattern
+// ('*' matches any sequence of characters)
+type KeyGlobToken struct {
+	Pattern string
+}
+
+// RecursiveDescentToken matches the value itself and all of its nested values
+// (map values and array items at any depth); optional token may match nothing
+type RecursiveDescentToken struct {
+	Optional bool
 }
 
 type Modifier interface {
@@ -36,3 +78,26 @@ type NextModifier struct{}
 
 type BeforeModifier struct{}
 type AfterModifier struct{}
+
+// BeforeMatchingModifier and AfterMatchingModifier place array item that is added
+// for an optional matching index token that does not match anything
+// next to the array item matching Predicates (ex: name=foo?:before=name=bar);
+// they do not affect array items that are found
+type BeforeMatchingModifier struct {
+	Predicates []MatchingPredicate
+}
+type AfterMatchingModifier struct {
+	Predicates []MatchingPredicate
+}
+
+// Selection modifiers choose which of the array items
+// matched by the matching index token are used
+type AllModifier struct{}
+type FirstModifier struct{}
+type LastModifier struct{}
+
+// NthModifier selects match by its zero-based position
+// (negative positions count from the last match)
+type NthModifier struct {
+	N int
+}
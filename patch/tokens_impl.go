@@ -6,20 +6,38 @@ var _ Token = AfterLastIndexToken{}
 var _ Token = MatchingIndexToken{}
 var _ Token = KeyToken{}
 var _ Token = WildcardToken{}
+var _ Token = KeyGlobToken{}
+var _ Token = RecursiveDescentToken{}
+var _ Token = RangeToken{}
 
-func (RootToken) _token()           {}
-func (IndexToken) _token()          {}
-func (AfterLastIndexToken) _token() {}
-func (MatchingIndexToken) _token()  {}
-func (KeyToken) _token()            {}
-func (WildcardToken) _token()       {}
+func (RootToken) _token()             {}
+func (IndexToken) _token()            {}
+func (AfterLastIndexToken) _token()   {}
+func (MatchingIndexToken) _token()    {}
+func (KeyToken) _token()              {}
+func (WildcardToken) _token()         {}
+func (KeyGlobToken) _token()          {}
+func (RecursiveDescentToken) _token() {}
+func (RangeToken) _token()            {}
 
 var _ Modifier = PrevModifier{}
 var _ Modifier = NextModifier{}
 var _ Modifier = BeforeModifier{}
 var _ Modifier = AfterModifier{}
+var _ Modifier = BeforeMatchingModifier{}
+var _ Modifier = AfterMatchingModifier{}
+var _ Modifier = AllModifier{}
+var _ Modifier = FirstModifier{}
+var _ Modifier = LastModifier{}
+var _ Modifier = NthModifier{}
 
-func (PrevModifier) _modifier()   {}
-func (NextModifier) _modifier()   {}
-func (BeforeModifier) _modifier() {}
-func (AfterModifier) _modifier()  {}
+func (PrevModifier) _modifier()           {}
+func (NextModifier) _modifier()           {}
+func (BeforeModifier) _modifier()         {}
+func (AfterModifier) _modifier()          {}
+func (BeforeMatchingModifier) _modifier() {}
+func (AfterMatchingModifier) _modifier()  {}
+func (AllModifier) _modifier()            {}
+func (FirstModifier) _modifier()          {}
+func (LastModifier) _modifier()           {}
+func (NthModifier) _modifier()            {}
@@ -0,0 +1,66 @@
+package patch
+
+import (
+	"fmt"
+)
+
+// UnionOp appends items of the array value to the array found at the path
+// unless the array already includes them; items are compared deeply
+// or by the value found at Key (which may refer to nested values, ex: release.name).
+// Existing items are kept as is (including duplicates).
+// Missing optional arrays are created (ex: /releases?).
+type UnionOp struct {
+	Path  Pointer
+	Value interface{} // will be cloned using yaml library
+	Key   string
+}
+
+func (op UnionOp) Apply(doc interface{}) (interface{}, error) {
+	items, err := arrayValue(op.Value, "union", op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	return arrayUpdate{
+		Path: op.Path,
+		Update: func(array []interface{}, path Pointer) ([]interface{}, error) {
+			for _, item := range items {
+				found, err := op.includes(array, item, path)
+				if err != nil {
+					return nil, err
+				}
+
+				if !found {
+					array = append(array, item)
+				}
+			}
+
+			return array, nil
+		},
+	}.Apply(doc)
+}
+
+// includes checks if array includes an item that is the same as given item
+func (op UnionOp) includes(array []interface{}, item interface{}, path Pointer) (bool, error) {
+	if len(op.Key) == 0 {
+		for _, arrayItem := range array {
+			if valuesEqual(arrayItem, item) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	keyVal, found := nestedValue(item, op.Key)
+	if !found {
+		return false, fmt.Errorf("Expected to find key '%s' in union item for path '%s'", op.Key, path)
+	}
+
+	predVal, err := exactMatchingValue(keyVal)
+	if err != nil {
+		errMsg := "Expected to find scalar key '%s' value in union item for path '%s' but found '%T'"
+		return false, fmt.Errorf(errMsg, op.Key, path, keyVal)
+	}
+
+	return len(matchingIndexes(MatchingIndexToken{Key: op.Key, Value: predVal}, array)) > 0, nil
+}
@@ -0,0 +1,90 @@
+package patch_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/stuart-pollock/go-patch/patch"
+)
+
+var _ = Describe("UnionOp.Apply", func() {
+	It("appends items that are not deeply equal to existing items", func() {
+		doc := map[interface{}]interface{}{
+			"azs": []interface{}{"z1", map[interface{}]interface{}{"name": "z2"}, "z1"},
+		}
+
+		res, err := UnionOp{
+			Path:  MustNewPointerFromString("/azs"),
+			Value: []interface{}{"z3", map[interface{}]interface{}{"name": "z2"}, "z1", "z3"},
+		}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(map[interface{}]interface{}{
+			"azs": []interface{}{"z1", map[interface{}]interface{}{"name": "z2"}, "z1", "z3"},
+		}))
+	})
+
+	It("appends items that do not have the same key value as existing items", func() {
+		doc := map[interface{}]interface{}{
+			"releases": []interface{}{
+				map[interface{}]interface{}{"name": "capi", "version": "1"},
+				map[interface{}]interface{}{"name": "80", "version": "1"},
+			},
+		}
+
+		res, err := UnionOp{
+			Path: MustNewPointerFromString("/releases"),
+			Value: []interface{}{
+				map[interface{}]interface{}{"name": "capi", "version": "2"},
+				map[interface{}]interface{}{"name": 80, "version": "2"},
+				map[interface{}]interface{}{"name": "syslog", "version": "1"},
+				map[interface{}]interface{}{"name": "syslog", "version": "2"},
+			},
+			Key: "name",
+		}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(map[interface{}]interface{}{
+			"releases": []interface{}{
+				map[interface{}]interface{}{"name": "capi", "version": "1"},
+				map[interface{}]interface{}{"name": "80", "version": "1"},
+				map[interface{}]interface{}{"name": 80, "version": "2"},
+				map[interface{}]interface{}{"name": "syslog", "version": "1"},
+			},
+		}))
+	})
+
+	It("creates missing optional arrays found via matching index tokens", func() {
+		doc := map[interface{}]interface{}{
+			"instance_groups": []interface{}{
+				map[interface{}]interface{}{"name": "api"},
+			},
+		}
+
+		res, err := UnionOp{
+			Path:  MustNewPointerFromString("/instance_groups/name=api/jobs?"),
+			Value: []interface{}{map[interface{}]interface{}{"name": "capi"}, map[interface{}]interface{}{"name": "capi"}},
+			Key:   "name",
+		}.Apply(doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(res).To(Equal(map[interface{}]interface{}{
+			"instance_groups": []interface{}{
+				map[interface{}]interface{}{
+					"name": "api",
+					"jobs": []interface{}{map[interface{}]interface{}{"name": "capi"}},
+				},
+			},
+		}))
+	})
+
+	It("returns an error if item does not have the key", func() {
+		_, err := UnionOp{
+			Path:  MustNewPointerFromString("/releases"),
+			Value: []interface{}{map[interface{}]interface{}{"version": "1"}},
+			Key:   "name",
+		}.Apply(map[interface{}]interface{}{"releases": []interface{}{}})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find key 'name' in union item for path '/releases'"))
+	})
+})
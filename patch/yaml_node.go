@@ -0,0 +1,506 @@
+package patch
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// ApplyToNode applies operation to a yaml.v3 node tree preserving comments,
+// key order, anchors, aliases and styles of values that were not changed.
+//
+// Operation is applied to the decoded document (so all tokens and modifiers
+// are supported) and resulting document is then reconciled with the original tree:
+// map keys keep their order (new keys are appended in sorted order),
+// array items are aligned by value, and changed scalars keep their comments and quoting style.
+// New values are rendered in block style unless they are placed into a flow collection.
+//
+// Given node is not modified; returned tree shares unchanged nodes with it.
+func ApplyToNode(op Op, node *yamlv3.Node) (*yamlv3.Node, error) {
+	var doc interface{}
+
+	if !isEmptyNode(node) {
+		err := node.Decode(&doc)
+		if err != nil {
+			return nil, fmt.Errorf("Decoding YAML node: %s", err)
+		}
+	}
+
+	doc, err := op.Apply(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	r := nodeReconciler{anchors: map[*yamlv3.Node]reconciledAnchor{}}
+
+	return r.Reconcile(node, doc, false)
+}
+
+func isEmptyNode(node *yamlv3.Node) bool {
+	if node == nil || node.Kind == 0 {
+		return true
+	}
+	return node.Kind == yamlv3.DocumentNode && len(node.Content) == 0
+}
+
+type reconciledAnchor struct {
+	Node  *yamlv3.Node
+	Value interface{}
+}
+
+type nodeReconciler struct {
+	// anchors keeps track of anchored nodes that were kept (keyed by original node)
+	// so that aliases referring to them could be kept as well
+	anchors map[*yamlv3.Node]reconciledAnchor
+}
+
+func (r nodeReconciler) Reconcile(node *yamlv3.Node, val interface{}, flow bool) (*yamlv3.Node, error) {
+	if node == nil || node.Kind == 0 {
+		return r.newNode(val, flow)
+	}
+
+	switch node.Kind {
+	case yamlv3.DocumentNode:
+		var content *yamlv3.Node
+		if len(node.Content) > 0 {
+			content = node.Content[0]
+		}
+
+		newContent, err := r.Reconcile(content, val, false)
+		if err != nil {
+			return nil, err
+		}
+
+		newNode := *node
+		newNode.Content = []*yamlv3.Node{newContent}
+		return &newNode, nil
+
+	case yamlv3.AliasNode:
+		if anchor, found := r.anchors[node.Alias]; found && valuesEqual(anchor.Value, val) {
+			newNode := *node
+			newNode.Alias = anchor.Node
+			return &newNode, nil
+		}
+
+		return r.replaceNode(node, val, flow)
+
+	case yamlv3.MappingNode:
+		typedVal, ok := newGenericMap(val)
+		if !ok {
+			return r.replaceNode(node, val, flow)
+		}
+
+		return r.rememberAnchor(node, val, func() (*yamlv3.Node, error) {
+			return r.reconcileMapping(node, typedVal, flow)
+		})
+
+	case yamlv3.SequenceNode:
+		typedVal, ok := val.([]interface{})
+		if !ok {
+			return r.replaceNode(node, val, flow)
+		}
+
+		return r.rememberAnchor(node, val, func() (*yamlv3.Node, error) {
+			return r.reconcileSequence(node, typedVal, flow)
+		})
+
+	case yamlv3.ScalarNode:
+		var nodeVal interface{}
+
+		err := node.Decode(&nodeVal)
+		if err != nil {
+			return nil, fmt.Errorf("Decoding YAML node: %s", err)
+		}
+
+		if !r.isScalar(val) || !valuesEqual(nodeVal, val) {
+			return r.replaceNode(node, val, flow)
+		}
+
+		return r.rememberAnchor(node, val, func() (*yamlv3.Node, error) { return node, nil })
+
+	default:
+		return nil, fmt.Errorf("Expected to find known YAML node kind but found '%d'", node.Kind)
+	}
+}
+
+func (r nodeReconciler) rememberAnchor(node *yamlv3.Node, val interface{}, reconcileFunc func() (*yamlv3.Node, error)) (*yamlv3.Node, error) {
+	newNode, err := reconcileFunc()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(node.Anchor) > 0 {
+		r.anchors[node] = reconciledAnchor{Node: newNode, Value: val}
+	}
+
+	return newNode, nil
+}
+
+func (r nodeReconciler) reconcileMapping(node *yamlv3.Node, val genericMap, flow bool) (*yamlv3.Node, error) {
+	flow = flow || node.Style&yamlv3.FlowStyle != 0
+
+	newNode := *node
+	newNode.Content = nil
+
+	seenKeys := map[interface{}]bool{}
+
+	var mergeKeys []int
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+
+		if keyNode.ShortTag() == "!!merge" {
+			mergeKeys = append(mergeKeys, i)
+			continue
+		}
+
+		var key interface{}
+
+		err := keyNode.Decode(&key)
+		if err != nil {
+			return nil, fmt.Errorf("Decoding YAML node: %s", err)
+		}
+
+		seenKeys[key] = true
+	}
+
+	// Merged maps are kept only if all inherited keys are still present;
+	// changed inherited values are overridden by explicit keys
+	keepMerges := len(mergeKeys) > 0
+
+	if keepMerges {
+		inheritedKeys, keep, err := r.inheritedKeys(node, mergeKeys, seenKeys, val)
+		if err != nil {
+			return nil, err
+		}
+
+		keepMerges = keep
+
+		if keepMerges {
+			for _, key := range inheritedKeys {
+				seenKeys[key] = true
+			}
+		}
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valNode := node.Content[i], node.Content[i+1]
+
+		if keyNode.ShortTag() == "!!merge" {
+			if keepMerges {
+				newNode.Content = append(newNode.Content, keyNode, r.relinkAliases(valNode))
+			}
+			continue
+		}
+
+		var key interface{}
+
+		err := keyNode.Decode(&key)
+		if err != nil {
+			return nil, fmt.Errorf("Decoding YAML node: %s", err)
+		}
+
+		newVal, found := val.Get(key)
+		if !found {
+			continue
+		}
+
+		newValNode, err := r.Reconcile(valNode, newVal, flow)
+		if err != nil {
+			return nil, err
+		}
+
+		newNode.Content = append(newNode.Content, keyNode, newValNode)
+	}
+
+	var newKeys []interface{}
+
+	for _, key := range val.Keys() {
+		if !seenKeys[key] {
+			newKeys = append(newKeys, key)
+		}
+	}
+
+	sort.Slice(newKeys, func(i, j int) bool {
+		return fmt.Sprintf("%v", newKeys[i]) < fmt.Sprintf("%v", newKeys[j])
+	})
+
+	for _, key := range newKeys {
+		newVal, _ := val.Get(key)
+
+		keyNode, err := r.newNode(key, flow)
+		if err != nil {
+			return nil, err
+		}
+
+		valNode, err := r.newNode(newVal, flow)
+		if err != nil {
+			return nil, err
+		}
+
+		newNode.Content = append(newNode.Content, keyNode, valNode)
+	}
+
+	return &newNode, nil
+}
+
+// inheritedKeys returns keys that are inherited via merge keys with unchanged values.
+// Merge keys cannot be kept if inherited key was removed or merged anchors changed.
+func (r nodeReconciler) inheritedKeys(node *yamlv3.Node, mergeKeys []int, explicitKeys map[interface{}]bool, val genericMap) ([]interface{}, bool, error) {
+	for _, i := range mergeKeys {
+		for _, alias := range r.mergedAliases(node.Content[i+1]) {
+			anchor, found := r.anchors[alias.Alias]
+			if !found {
+				return nil, false, nil
+			}
+
+			var aliasVal interface{}
+
+			err := alias.Decode(&aliasVal)
+			if err != nil {
+				return nil, false, fmt.Errorf("Decoding YAML node: %s", err)
+			}
+
+			if !valuesEqual(aliasVal, anchor.Value) {
+				return nil, false, nil
+			}
+		}
+	}
+
+	var nodeVal interface{}
+
+	err := node.Decode(&nodeVal)
+	if err != nil {
+		return nil, false, fmt.Errorf("Decoding YAML node: %s", err)
+	}
+
+	nodeMap, _ := newGenericMap(nodeVal)
+
+	var keys []interface{}
+
+	for _, key := range nodeMap.Keys() {
+		if explicitKeys[key] {
+			continue
+		}
+
+		newVal, found := val.Get(key)
+		if !found {
+			return nil, false, nil
+		}
+
+		if oldVal, _ := nodeMap.Get(key); valuesEqual(oldVal, newVal) {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys, true, nil
+}
+
+func (nodeReconciler) mergedAliases(node *yamlv3.Node) []*yamlv3.Node {
+	switch node.Kind {
+	case yamlv3.AliasNode:
+		return []*yamlv3.Node{node}
+	case yamlv3.SequenceNode:
+		var aliases []*yamlv3.Node
+		for _, item := range node.Content {
+			if item.Kind == yamlv3.AliasNode {
+				aliases = append(aliases, item)
+			}
+		}
+		return aliases
+	default:
+		return nil
+	}
+}
+
+// relinkAliases points merged aliases to reconciled anchored nodes
+func (r nodeReconciler) relinkAliases(node *yamlv3.Node) *yamlv3.Node {
+	switch node.Kind {
+	case yamlv3.AliasNode:
+		newNode := *node
+		newNode.Alias = r.anchors[node.Alias].Node
+		return &newNode
+
+	case yamlv3.SequenceNode:
+		newNode := *node
+		newNode.Content = nil
+		for _, item := range node.Content {
+			newNode.Content = append(newNode.Content, r.relinkAliases(item))
+		}
+		return &newNode
+
+	default:
+		return node
+	}
+}
+
+func (r nodeReconciler) reconcileSequence(node *yamlv3.Node, val []interface{}, flow bool) (*yamlv3.Node, error) {
+	flow = flow || node.Style&yamlv3.FlowStyle != 0
+
+	var nodeVals []interface{}
+
+	for _, item := range node.Content {
+		var itemVal interface{}
+
+		err := item.Decode(&itemVal)
+		if err != nil {
+			return nil, fmt.Errorf("Decoding YAML node: %s", err)
+		}
+
+		nodeVals = append(nodeVals, itemVal)
+	}
+
+	newNode := *node
+	newNode.Content = nil
+
+	// Items in between equal items are paired up by position
+	// so that modified items keep their comments and styles
+	appendItems := func(items []*yamlv3.Node, vals []interface{}) error {
+		for i, itemVal := range vals {
+			var itemNode *yamlv3.Node
+			if i < len(items) {
+				itemNode = items[i]
+			}
+
+			newItemNode, err := r.Reconcile(itemNode, itemVal, flow)
+			if err != nil {
+				return err
+			}
+
+			newNode.Content = append(newNode.Content, newItemNode)
+		}
+		return nil
+	}
+
+	var lastNodeIdx, lastValIdx int
+
+	for _, pair := range r.equalItems(nodeVals, val) {
+		err := appendItems(node.Content[lastNodeIdx:pair[0]], val[lastValIdx:pair[1]])
+		if err != nil {
+			return nil, err
+		}
+
+		err = appendItems(node.Content[pair[0]:pair[0]+1], val[pair[1]:pair[1]+1])
+		if err != nil {
+			return nil, err
+		}
+
+		lastNodeIdx, lastValIdx = pair[0]+1, pair[1]+1
+	}
+
+	err := appendItems(node.Content[lastNodeIdx:], val[lastValIdx:])
+	if err != nil {
+		return nil, err
+	}
+
+	return &newNode, nil
+}
+
+// equalItems returns pairs of indices of equal items
+// based on the longest common subsequence of both arrays
+func (nodeReconciler) equalItems(left, right []interface{}) [][2]int {
+	lengths := make([][]int, len(left)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(right)+1)
+	}
+
+	for i := len(left) - 1; i >= 0; i-- {
+		for j := len(right) - 1; j >= 0; j-- {
+			switch {
+			case valuesEqual(left[i], right[j]):
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			case lengths[i+1][j] >= lengths[i][j+1]:
+				lengths[i][j] = lengths[i+1][j]
+			default:
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+
+	var pairs [][2]int
+
+	for i, j := 0, 0; i < len(left) && j < len(right); {
+		switch {
+		case valuesEqual(left[i], right[j]):
+			pairs = append(pairs, [2]int{i, j})
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+
+	return pairs
+}
+
+// replaceNode creates a node for the new value keeping comments
+// and quoting style of the original node where possible
+func (r nodeReconciler) replaceNode(node *yamlv3.Node, val interface{}, flow bool) (*yamlv3.Node, error) {
+	newNode, err := r.newNode(val, flow)
+	if err != nil {
+		return nil, err
+	}
+
+	isStr := func(n *yamlv3.Node) bool { return n.Kind == yamlv3.ScalarNode && n.ShortTag() == "!!str" }
+
+	if isStr(node) && isStr(newNode) {
+		quotedStyle := node.Style & (yamlv3.SingleQuotedStyle | yamlv3.DoubleQuotedStyle)
+		blockStyle := node.Style & (yamlv3.LiteralStyle | yamlv3.FoldedStyle)
+		multiline := strings.Contains(newNode.Value, "\n")
+
+		switch {
+		case quotedStyle != 0 && !multiline:
+			newNode.Style = quotedStyle
+		case blockStyle != 0 && multiline && !flow:
+			newNode.Style = blockStyle
+		}
+	}
+
+	newNode.HeadComment = node.HeadComment
+	newNode.LineComment = node.LineComment
+	newNode.FootComment = node.FootComment
+
+	return newNode, nil
+}
+
+func (nodeReconciler) newNode(val interface{}, flow bool) (*yamlv3.Node, error) {
+	newNode := &yamlv3.Node{}
+
+	err := newNode.Encode(val)
+	if err != nil {
+		return nil, fmt.Errorf("Encoding YAML node: %s", err)
+	}
+
+	if flow {
+		setFlowStyle(newNode)
+	}
+
+	return newNode, nil
+}
+
+func (nodeReconciler) isScalar(val interface{}) bool {
+	if _, ok := newGenericMap(val); ok {
+		return false
+	}
+	_, ok := val.([]interface{})
+	return !ok
+}
+
+func setFlowStyle(node *yamlv3.Node) {
+	switch node.Kind {
+	case yamlv3.MappingNode, yamlv3.SequenceNode:
+		node.Style |= yamlv3.FlowStyle
+		for _, item := range node.Content {
+			setFlowStyle(item)
+		}
+
+	case yamlv3.ScalarNode:
+		if node.Style&(yamlv3.LiteralStyle|yamlv3.FoldedStyle) != 0 {
+			node.Style = yamlv3.DoubleQuotedStyle
+		}
+	}
+}
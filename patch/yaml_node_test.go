@@ -0,0 +1,181 @@
+package patch_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	yamlv3 "gopkg.in/yaml.v3"
+
+	. "github.com/stuart-pollock/go-patch/patch"
+)
+
+var _ = Describe("ApplyToNode", func() {
+	apply := func(op Op, in string) string {
+		var node yamlv3.Node
+
+		err := yamlv3.Unmarshal([]byte(in), &node)
+		Expect(err).ToNot(HaveOccurred())
+
+		res, err := ApplyToNode(op, &node)
+		Expect(err).ToNot(HaveOccurred())
+
+		bs, err := yamlv3.Marshal(res)
+		Expect(err).ToNot(HaveOccurred())
+
+		return string(bs)
+	}
+
+	It("keeps comments, key order and styles of unchanged values", func() {
+		in := `# Deployment
+name: dep # name
+instance_groups:
+# API
+- name: api
+  instances: 1
+  azs: [z1, z2]
+  properties:
+    motd: |
+      hello
+      world
+    quoted: "yes"
+- name: worker # worker
+  instances: 2
+`
+
+		Expect(apply(Ops{
+			ReplaceOp{Path: MustNewPointerFromString("/instance_groups/name=api/instances"), Value: 3},
+			ReplaceOp{Path: MustNewPointerFromString("/instance_groups/name=worker/env?/persistent_disk_fs"), Value: "ext4"},
+		}, in)).To(Equal(`# Deployment
+name: dep # name
+instance_groups:
+    # API
+    - name: api
+      instances: 3
+      azs: [z1, z2]
+      properties:
+        motd: |
+            hello
+            world
+        quoted: "yes"
+    - name: worker # worker
+      instances: 2
+      env:
+        persistent_disk_fs: ext4
+`))
+	})
+
+	It("appends new map keys in sorted order", func() {
+		Expect(apply(MergePatchOp{
+			Path:  MustNewPointerFromString(""),
+			Value: map[interface{}]interface{}{"c": 1, "b": 2, "z": nil},
+		}, "z: 1\na: 1\n")).To(Equal("a: 1\nb: 2\nc: 1\n"))
+	})
+
+	It("keeps comments and quoting style of replaced values", func() {
+		Expect(apply(
+			ReplaceOp{Path: MustNewPointerFromString("/a"), Value: "new"},
+			"# head\na: 'old' # line\n",
+		)).To(Equal("# head\na: 'new' # line\n"))
+
+		Expect(apply(
+			ReplaceOp{Path: MustNewPointerFromString("/a"), Value: "new\nlines\n"},
+			"a: |\n  old\n  lines\n",
+		)).To(Equal("a: |\n    new\n    lines\n"))
+	})
+
+	It("aligns array items by value", func() {
+		in := `- a # first
+- b # second
+- c # third
+`
+
+		Expect(apply(RemoveOp{Path: MustNewPointerFromString("/0")}, in)).To(Equal("- b # second\n- c # third\n"))
+
+		Expect(apply(ReplaceOp{Path: MustNewPointerFromString("/1:before"), Value: "new"}, in)).To(
+			Equal("- a # first\n- new\n- b # second\n- c # third\n"))
+
+		Expect(apply(ReplaceOp{Path: MustNewPointerFromString("/1"), Value: "new"}, in)).To(
+			Equal("- a # first\n- new # second\n- c # third\n"))
+	})
+
+	It("uses flow style for values inserted into flow collections", func() {
+		Expect(apply(
+			ReplaceOp{Path: MustNewPointerFromString("/azs/-"), Value: map[interface{}]interface{}{"name": "z3"}},
+			"azs: [z1, z2]\n",
+		)).To(Equal("azs: [z1, z2, {name: z3}]\n"))
+	})
+
+	It("keeps anchors and aliases when referenced values are unchanged", func() {
+		Expect(apply(
+			ReplaceOp{Path: MustNewPointerFromString("/api/instances"), Value: 2},
+			`defaults: &defaults
+  vm_type: small
+api:
+  <<: *defaults
+  instances: 1
+`,
+		)).To(Equal(`defaults: &defaults
+    vm_type: small
+api:
+    !!merge <<: *defaults
+    instances: 2
+`))
+	})
+
+	It("overrides changed merged values and expands merge if inherited key is removed", func() {
+		in := `defaults: &defaults
+  vm_type: small
+  stemcell: default
+api:
+  <<: *defaults
+`
+
+		Expect(apply(
+			ReplaceOp{Path: MustNewPointerFromString("/api/vm_type"), Value: "large"}, in,
+		)).To(Equal(`defaults: &defaults
+    vm_type: small
+    stemcell: default
+api:
+    !!merge <<: *defaults
+    vm_type: large
+`))
+
+		Expect(apply(
+			RemoveOp{Path: MustNewPointerFromString("/api/vm_type")}, in,
+		)).To(Equal(`defaults: &defaults
+    vm_type: small
+    stemcell: default
+api:
+    stemcell: default
+`))
+	})
+
+	It("expands aliases when anchored value changes", func() {
+		in := `a: &val x
+b: *val
+`
+
+		Expect(apply(ReplaceOp{Path: MustNewPointerFromString("/b"), Value: "z"}, in)).To(Equal("a: &val x\nb: z\n"))
+		Expect(apply(ReplaceOp{Path: MustNewPointerFromString("/a"), Value: "z"}, in)).To(Equal("a: z\nb: x\n"))
+		Expect(apply(ReplaceOp{Path: MustNewPointerFromString("/c?"), Value: "z"}, in)).To(Equal("a: &val x\nb: *val\nc: z\n"))
+	})
+
+	It("creates document from empty node", func() {
+		res, err := ApplyToNode(ReplaceOp{Path: MustNewPointerFromString(""), Value: map[interface{}]interface{}{"a": 1}}, &yamlv3.Node{})
+		Expect(err).ToNot(HaveOccurred())
+
+		bs, err := yamlv3.Marshal(res)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(bs)).To(Equal("a: 1\n"))
+	})
+
+	It("returns error if operation fails", func() {
+		var node yamlv3.Node
+
+		err := yamlv3.Unmarshal([]byte("a: 1"), &node)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = ApplyToNode(FindOp{Path: MustNewPointerFromString("/b")}, &node)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find a map key 'b' for path '/b' (found map keys: 'a')"))
+	})
+})